@@ -0,0 +1,63 @@
+// Package httppush sends the requests of outputs pushing metrics to an HTTP
+// endpoint, classifying the errors that are worth retrying.
+package httppush
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// maxErrorBody is the maximum length of response bodies included in errors.
+const maxErrorBody = 1024
+
+// recoverableError is the error of a request that may succeed if retried.
+type recoverableError struct {
+	error
+}
+
+// IsRecoverable returns true if the error is a network error or a server
+// error, the request may then succeed if retried.  Other errors are caused by
+// the request, which would be rejected again.
+func IsRecoverable(err error) bool {
+	_, ok := err.(recoverableError)
+	return ok
+}
+
+// Do sends the request, returning an error including the beginning of the
+// response body if the status code is not 2xx.
+func Do(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return recoverableError{err}
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return StatusError(req, resp.StatusCode, body)
+}
+
+// StatusError returns the error for a response with an unexpected status
+// code, including the beginning of the response body.  The error is
+// recoverable for server errors and when being rate limited.
+func StatusError(req *http.Request, statusCode int, body []byte) error {
+	err := fmt.Errorf("when writing to [%s] received status code: %d, body: %s",
+		req.URL, statusCode, ErrorBody(body))
+	if statusCode >= 500 || statusCode == http.StatusTooManyRequests {
+		return recoverableError{err}
+	}
+	return err
+}
+
+// ErrorBody returns the beginning of a response body for inclusion in an
+// error.
+func ErrorBody(body []byte) string {
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody]
+	}
+	return string(bytes.TrimSpace(body))
+}
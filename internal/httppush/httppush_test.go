@@ -0,0 +1,57 @@
+package httppush
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDo(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		err         bool
+		recoverable bool
+	}{
+		{"success", http.StatusNoContent, false, false},
+		{"bad request", http.StatusBadRequest, true, false},
+		{"too many requests", http.StatusTooManyRequests, true, true},
+		{"service unavailable", http.StatusServiceUnavailable, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(strings.Repeat("x", 2*maxErrorBody)))
+			}))
+			defer ts.Close()
+
+			req, err := http.NewRequest(http.MethodPost, ts.URL, nil)
+			require.NoError(t, err)
+
+			err = Do(http.DefaultClient, req)
+			if !tt.err {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, tt.recoverable, IsRecoverable(err))
+			require.True(t, len(err.Error()) < 2*maxErrorBody)
+		})
+	}
+}
+
+func TestDoNetworkError(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	ts.Close()
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL, nil)
+	require.NoError(t, err)
+
+	err = Do(http.DefaultClient, req)
+	require.Error(t, err)
+	require.True(t, IsRecoverable(err))
+}
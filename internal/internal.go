@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"crypto/rand"
 	"errors"
+	"fmt"
 	"log"
 	"math/big"
 	"os"
@@ -63,6 +64,52 @@ func (d *Duration) UnmarshalTOML(b []byte) error {
 	return nil
 }
 
+// Size is a number of bytes
+type Size struct {
+	Size int64
+}
+
+var sizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"kb":  1000,
+	"mb":  1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// UnmarshalTOML parses the size from the TOML config file, either as an
+// integer number of bytes or as a string with a unit, ie, "10MB" or "1GiB"
+func (s *Size) UnmarshalTOML(b []byte) error {
+	str := string(bytes.Trim(b, `'`))
+	if uq, err := strconv.Unquote(str); err == nil {
+		str = uq
+	}
+	str = strings.TrimSpace(str)
+
+	i := strings.IndexFunc(str, func(r rune) bool {
+		return !unicode.IsDigit(r)
+	})
+	if i == -1 {
+		i = len(str)
+	}
+
+	n, err := strconv.ParseInt(str[:i], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid size %q", str)
+	}
+	unit, ok := sizeUnits[strings.ToLower(strings.TrimSpace(str[i:]))]
+	if !ok {
+		return fmt.Errorf("invalid size unit in %q", str)
+	}
+	s.Size = n * unit
+	return nil
+}
+
 // ReadLines reads contents from a file and splits them by new lines.
 // A convenience wrapper to ReadLinesOffsetN(filename, 0, -1).
 func ReadLines(filename string) ([]string, error) {
@@ -193,3 +240,52 @@ func RandomSleep(max time.Duration, shutdown chan struct{}) {
 		return
 	}
 }
+
+// ParseTimestamp parses a timestamp value using format, which may be a Go
+// reference time layout or one of "unix", "unix_ms", "unix_us" and "unix_ns".
+// Layouts without a timezone are interpreted as UTC.
+func ParseTimestamp(timestamp interface{}, format string) (time.Time, error) {
+	return ParseTimestampWithLocation(timestamp, format, time.UTC)
+}
+
+// ParseTimestampWithLocation parses a timestamp value like ParseTimestamp,
+// interpreting layouts without a timezone in location.
+func ParseTimestampWithLocation(timestamp interface{}, format string, location *time.Location) (time.Time, error) {
+	var unit time.Duration
+	switch format {
+	case "unix":
+		unit = time.Second
+	case "unix_ms":
+		unit = time.Millisecond
+	case "unix_us":
+		unit = time.Microsecond
+	case "unix_ns":
+		unit = time.Nanosecond
+	default:
+		s, ok := timestamp.(string)
+		if !ok {
+			return time.Time{}, fmt.Errorf("unsupported type %T for timestamp format %q", timestamp, format)
+		}
+		return time.ParseInLocation(format, s, location)
+	}
+
+	switch v := timestamp.(type) {
+	case int64:
+		return time.Unix(0, v*int64(unit)), nil
+	case uint64:
+		return time.Unix(0, int64(v)*int64(unit)), nil
+	case float64:
+		return time.Unix(0, int64(v*float64(unit))), nil
+	case string:
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.Unix(0, i*int64(unit)), nil
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(0, int64(f*float64(unit))), nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported type %T for timestamp format %q", timestamp, format)
+	}
+}
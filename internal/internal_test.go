@@ -162,3 +162,69 @@ func TestDuration(t *testing.T) {
 	d.UnmarshalTOML([]byte(`1.5`))
 	assert.Equal(t, time.Second, d.Duration)
 }
+
+func TestSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{input: `1024`, expected: 1024},
+		{input: `"1024"`, expected: 1024},
+		{input: `"10B"`, expected: 10},
+		{input: `"10MB"`, expected: 10 * 1000 * 1000},
+		{input: `'1GiB'`, expected: 1 << 30},
+		{input: `"2 kib"`, expected: 2048},
+	}
+	for _, tt := range tests {
+		var s Size
+		assert.NoError(t, s.UnmarshalTOML([]byte(tt.input)))
+		assert.Equal(t, tt.expected, s.Size)
+	}
+
+	var s Size
+	assert.Error(t, s.UnmarshalTOML([]byte(`"MB"`)))
+	assert.Error(t, s.UnmarshalTOML([]byte(`"10PB"`)))
+}
+
+func TestParseTimestamp(t *testing.T) {
+	expected := time.Unix(1517097600, 0)
+	tests := []struct {
+		value  interface{}
+		format string
+	}{
+		{int64(1517097600), "unix"},
+		{"1517097600", "unix"},
+		{1517097600.0, "unix"},
+		{int64(1517097600000), "unix_ms"},
+		{uint64(1517097600000000), "unix_us"},
+		{"1517097600000000000", "unix_ns"},
+		{"2018-01-28T00:00:00Z", time.RFC3339},
+		{"2018-01-28 00:00:00", "2006-01-02 15:04:05"},
+	}
+
+	for _, tt := range tests {
+		actual, err := ParseTimestamp(tt.value, tt.format)
+		assert.NoError(t, err)
+		assert.Equal(t, expected.UnixNano(), actual.UnixNano(), "%v %s", tt.value, tt.format)
+	}
+}
+
+func TestParseTimestampWithLocation(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	actual, err := ParseTimestampWithLocation("2018-01-28 00:00:00", "2006-01-02 15:04:05", loc)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2018, 1, 28, 5, 0, 0, 0, time.UTC).UnixNano(), actual.UnixNano())
+}
+
+func TestParseTimestampInvalid(t *testing.T) {
+	_, err := ParseTimestamp(true, "unix")
+	assert.Error(t, err)
+
+	_, err = ParseTimestamp(int64(42), time.RFC3339)
+	assert.Error(t, err)
+
+	_, err = ParseTimestamp("yesterday", "unix")
+	assert.Error(t, err)
+}
@@ -0,0 +1,39 @@
+// Package keypath selects values of decoded documents by path, with the keys
+// of nested objects separated by dots.
+package keypath
+
+import (
+	"strings"
+)
+
+// Lookup returns the value at path, or nil if it is not set.
+func Lookup(obj map[string]interface{}, path string) interface{} {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := obj[key].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		obj = child
+	}
+	return obj[keys[len(keys)-1]]
+}
+
+// Remove deletes the value at path.
+func Remove(obj map[string]interface{}, path string) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := obj[key].(map[string]interface{})
+		if !ok {
+			return
+		}
+		obj = child
+	}
+	delete(obj, keys[len(keys)-1])
+}
+
+// Name returns the tag or field key of the value at path, the keys are
+// joined with underscores as when flattening nested objects into fields.
+func Name(path string) string {
+	return strings.Replace(path, ".", "_", -1)
+}
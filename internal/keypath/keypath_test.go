@@ -0,0 +1,42 @@
+package keypath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testObject() map[string]interface{} {
+	return map[string]interface{}{
+		"name": "cpu",
+		"host": map[string]interface{}{
+			"name":   "server01",
+			"region": "us-east",
+		},
+	}
+}
+
+func TestLookup(t *testing.T) {
+	obj := testObject()
+	assert.Equal(t, "cpu", Lookup(obj, "name"))
+	assert.Equal(t, "server01", Lookup(obj, "host.name"))
+	assert.Nil(t, Lookup(obj, "host.missing"))
+	assert.Nil(t, Lookup(obj, "name.missing"))
+}
+
+func TestRemove(t *testing.T) {
+	obj := testObject()
+	Remove(obj, "host.name")
+	Remove(obj, "name.missing")
+	assert.Equal(t, map[string]interface{}{
+		"name": "cpu",
+		"host": map[string]interface{}{
+			"region": "us-east",
+		},
+	}, obj)
+}
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "name", Name("name"))
+	assert.Equal(t, "host_region", Name("host.region"))
+}
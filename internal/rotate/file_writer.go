@@ -0,0 +1,213 @@
+package rotate
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// archiveTimeFormat is the timestamp added to the name of rotated files, the
+// nanoseconds are appended to keep archive names unique and sortable.
+const archiveTimeFormat = "20060102T150405"
+
+// FileWriter is a writer appending to a file, rotating it when it becomes
+// older than an interval or larger than a size.  Rotated files are renamed
+// with a timestamp before the extension, optionally compressed with gzip,
+// and only the most recent archives are kept.
+type FileWriter struct {
+	filename    string
+	interval    time.Duration
+	maxSize     int64
+	maxArchives int
+	compress    bool
+	archiveRE   *regexp.Regexp
+	now         func() time.Time
+
+	sync.Mutex
+	current      *os.File
+	expireTime   time.Time
+	bytesWritten int64
+}
+
+// NewFileWriter opens the file for appending.  An interval or maxSize of 0
+// disables rotation based on age or size, and maxArchives of -1 keeps all
+// rotated files.
+func NewFileWriter(filename string, interval time.Duration, maxSize int64, maxArchives int, compress bool) (*FileWriter, error) {
+	prefix, ext := splitExt(filename)
+	w := &FileWriter{
+		filename:    filename,
+		interval:    interval,
+		maxSize:     maxSize,
+		maxArchives: maxArchives,
+		compress:    compress,
+		archiveRE: regexp.MustCompile("^" + regexp.QuoteMeta(filepath.Base(prefix)) +
+			`\.\d{8}T\d{15}` + regexp.QuoteMeta(ext) + `(\.gz)?$`),
+		now: time.Now,
+	}
+
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write writes p to the file, rotating the file first if needed.
+func (w *FileWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.current == nil {
+		return 0, fmt.Errorf("file %s is closed", w.filename)
+	}
+
+	if w.needsRotation(len(p)) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.current.Write(p)
+	w.bytesWritten += int64(n)
+	return n, err
+}
+
+// Close closes the current file.
+func (w *FileWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.current == nil {
+		return nil
+	}
+	err := w.current.Close()
+	w.current = nil
+	return err
+}
+
+func (w *FileWriter) open() error {
+	f, err := os.OpenFile(w.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	w.current = f
+	w.bytesWritten = stat.Size()
+	w.expireTime = w.now().Add(w.interval)
+	return nil
+}
+
+func (w *FileWriter) needsRotation(size int) bool {
+	if w.interval > 0 && !w.now().Before(w.expireTime) {
+		return true
+	}
+	return w.maxSize > 0 && w.bytesWritten > 0 && w.bytesWritten+int64(size) > w.maxSize
+}
+
+// rotate archives the current file and opens a new one.  Failing to archive
+// the file is only logged, the file is always reopened so that writing
+// continues.
+func (w *FileWriter) rotate() error {
+	if err := w.current.Close(); err != nil {
+		log.Printf("E! Unable to close file %s: %v", w.filename, err)
+	}
+	w.current = nil
+
+	now := w.now()
+	prefix, ext := splitExt(w.filename)
+	archive := fmt.Sprintf("%s.%s%09d%s", prefix, now.Format(archiveTimeFormat), now.Nanosecond(), ext)
+	if err := os.Rename(w.filename, archive); err != nil {
+		log.Printf("E! Unable to rotate file %s: %v", w.filename, err)
+		return w.open()
+	}
+
+	if w.compress {
+		if err := compressFile(archive); err != nil {
+			log.Printf("E! Unable to compress file %s: %v", archive, err)
+		}
+	}
+
+	if err := w.purgeArchives(); err != nil {
+		log.Printf("E! Unable to remove archives of file %s: %v", w.filename, err)
+	}
+	return w.open()
+}
+
+// purgeArchives removes the oldest archives exceeding maxArchives.
+func (w *FileWriter) purgeArchives() error {
+	if w.maxArchives < 0 {
+		return nil
+	}
+
+	dir := filepath.Dir(w.filename)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var archives []string
+	for _, file := range files {
+		if w.archiveRE.MatchString(file.Name()) {
+			archives = append(archives, file.Name())
+		}
+	}
+	if len(archives) <= w.maxArchives {
+		return nil
+	}
+
+	sort.Strings(archives)
+	for _, archive := range archives[:len(archives)-w.maxArchives] {
+		if err := os.Remove(filepath.Join(dir, archive)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compressFile replaces the file with a gzip compressed copy.
+func compressFile(filename string) error {
+	src, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(filename+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(filename)
+}
+
+// splitExt splits the filename into the path without the extension and the
+// extension.
+func splitExt(filename string) (string, string) {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext), ext
+}
@@ -0,0 +1,194 @@
+package rotate
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "rotate")
+	require.NoError(t, err)
+	return dir
+}
+
+func listFiles(t *testing.T, dir string) []string {
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// clock returns a time function advanced by one second on every call.
+func clock() func() time.Time {
+	now := time.Date(2018, 6, 24, 10, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+}
+
+func TestAppend(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "metrics.out")
+	require.NoError(t, ioutil.WriteFile(filename, []byte("a\n"), 0644))
+
+	w, err := NewFileWriter(filename, 0, 0, -1, false)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("b\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	buf, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "a\nb\n", string(buf))
+
+	_, err = w.Write([]byte("c\n"))
+	assert.Error(t, err)
+}
+
+func TestRotateBySize(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "metrics.out")
+
+	w, err := NewFileWriter(filename, 0, 10, -1, false)
+	require.NoError(t, err)
+	w.now = clock()
+
+	for _, line := range []string{"0123\n", "0123\n", "0\n"} {
+		_, err = w.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, []string{
+		"metrics.20180624T100001000000000.out",
+		"metrics.out",
+	}, listFiles(t, dir))
+
+	buf, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "0\n", string(buf))
+}
+
+func TestRotateByInterval(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "metrics.out")
+
+	w, err := NewFileWriter(filename, 2*time.Second, 0, -1, false)
+	require.NoError(t, err)
+	w.now = clock()
+	w.expireTime = time.Date(2018, 6, 24, 10, 0, 2, 0, time.UTC)
+
+	// the clock advances once per check, and once per rotation
+	for i := 0; i < 3; i++ {
+		_, err = w.Write([]byte("line\n"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, []string{
+		"metrics.20180624T100003000000000.out",
+		"metrics.out",
+	}, listFiles(t, dir))
+}
+
+func TestMaxArchivesAndCompress(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "metrics.out")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "metrics.other.out"), nil, 0644))
+
+	w, err := NewFileWriter(filename, 0, 1, 2, true)
+	require.NoError(t, err)
+	w.now = clock()
+
+	for _, line := range []string{"a\n", "b\n", "c\n", "d\n"} {
+		_, err = w.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, []string{
+		"metrics.20180624T100003000000000.out.gz",
+		"metrics.20180624T100005000000000.out.gz",
+		"metrics.other.out",
+		"metrics.out",
+	}, listFiles(t, dir))
+
+	f, err := os.Open(filepath.Join(dir, "metrics.20180624T100005000000000.out.gz"))
+	require.NoError(t, err)
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	buf, err := ioutil.ReadAll(zr)
+	require.NoError(t, err)
+	assert.Equal(t, "c\n", string(buf))
+}
+
+func TestRotateRenameFailure(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "metrics.out")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "metrics.20180624T100001000000000.out"), 0755))
+
+	w, err := NewFileWriter(filename, 0, 1, -1, false)
+	require.NoError(t, err)
+	w.now = clock()
+
+	for _, line := range []string{"a\n", "b\n", "c\n"} {
+		_, err = w.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	buf, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "c\n", string(buf))
+	assert.Equal(t, []string{
+		"metrics.20180624T100001000000000.out",
+		"metrics.20180624T100003000000000.out",
+		"metrics.out",
+	}, listFiles(t, dir))
+}
+
+func TestRotateCompressFailure(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "metrics.out")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "metrics.20180624T100001000000000.out.gz"), 0755))
+
+	w, err := NewFileWriter(filename, 0, 1, -1, true)
+	require.NoError(t, err)
+	w.now = clock()
+
+	for _, line := range []string{"a\n", "b\n"} {
+		_, err = w.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, []string{
+		"metrics.20180624T100001000000000.out",
+		"metrics.20180624T100001000000000.out.gz",
+		"metrics.out",
+	}, listFiles(t, dir))
+
+	buf, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "b\n", string(buf))
+}
@@ -116,6 +116,16 @@ func TestAddFieldOverwrites(t *testing.T) {
 	require.Equal(t, 42.0, value)
 }
 
+func TestAddFieldExistingDoesNotAppend(t *testing.T) {
+	m := baseMetric()
+
+	m.AddField("value", 42.0)
+
+	require.Len(t, m.FieldList(), 1)
+	m.RemoveField("value")
+	require.False(t, m.HasField("value"))
+}
+
 func TestAddFieldChangesType(t *testing.T) {
 	m := baseMetric()
 
@@ -3,9 +3,7 @@
 package logparser
 
 import (
-	"fmt"
 	"log"
-	"reflect"
 	"strings"
 	"sync"
 
@@ -14,19 +12,20 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal/globpath"
 	"github.com/influxdata/telegraf/plugins/inputs"
-
-	// Parsers
-	"github.com/influxdata/telegraf/plugins/inputs/logparser/grok"
+	"github.com/influxdata/telegraf/plugins/parsers"
 )
 
 const (
 	defaultWatchMethod = "inotify"
 )
 
-// LogParser in the primary interface for the plugin
-type LogParser interface {
-	ParseLine(line string) (telegraf.Metric, error)
-	Compile() error
+// GrokConfig holds the options of the grok parser used to parse the lines.
+type GrokConfig struct {
+	MeasurementName    string `toml:"measurement"`
+	Patterns           []string
+	CustomPatterns     string
+	CustomPatternFiles []string
+	Timezone           string
 }
 
 type logEntry struct {
@@ -40,16 +39,16 @@ type LogParserPlugin struct {
 	FromBeginning bool
 	WatchMethod   string
 
-	tailers map[string]*tail.Tail
-	lines   chan logEntry
-	done    chan struct{}
-	wg      sync.WaitGroup
-	acc     telegraf.Accumulator
-	parsers []LogParser
+	tailers    map[string]*tail.Tail
+	lines      chan logEntry
+	done       chan struct{}
+	wg         sync.WaitGroup
+	acc        telegraf.Accumulator
+	grokParser parsers.Parser
 
 	sync.Mutex
 
-	GrokParser *grok.Parser `toml:"grok"`
+	GrokConfig GrokConfig `toml:"grok"`
 }
 
 const sampleConfig = `
@@ -130,34 +129,24 @@ func (l *LogParserPlugin) Start(acc telegraf.Accumulator) error {
 	l.done = make(chan struct{})
 	l.tailers = make(map[string]*tail.Tail)
 
-	// Looks for fields which implement LogParser interface
-	l.parsers = []LogParser{}
-	s := reflect.ValueOf(l).Elem()
-	for i := 0; i < s.NumField(); i++ {
-		f := s.Field(i)
-
-		if !f.CanInterface() {
-			continue
-		}
-
-		if lpPlugin, ok := f.Interface().(LogParser); ok {
-			if reflect.ValueOf(lpPlugin).IsNil() {
-				continue
-			}
-			l.parsers = append(l.parsers, lpPlugin)
-		}
-	}
-
-	if len(l.parsers) == 0 {
-		return fmt.Errorf("logparser input plugin: no parser defined")
+	// the grok parser defaults to the logparser_grok measurement
+	mName := "logparser_grok"
+	if l.GrokConfig.MeasurementName != "" {
+		mName = l.GrokConfig.MeasurementName
 	}
 
-	// compile log parser patterns:
-	for _, parser := range l.parsers {
-		if err := parser.Compile(); err != nil {
-			return err
-		}
+	parser, err := parsers.NewParser(&parsers.Config{
+		MetricName:             mName,
+		GrokPatterns:           l.GrokConfig.Patterns,
+		GrokCustomPatterns:     l.GrokConfig.CustomPatterns,
+		GrokCustomPatternFiles: l.GrokConfig.CustomPatternFiles,
+		GrokTimezone:           l.GrokConfig.Timezone,
+		DataFormat:             "grok",
+	})
+	if err != nil {
+		return err
 	}
+	l.grokParser = parser
 
 	l.wg.Add(1)
 	go l.parser()
@@ -265,17 +254,15 @@ func (l *LogParserPlugin) parser() {
 				continue
 			}
 		}
-		for _, parser := range l.parsers {
-			m, err = parser.ParseLine(entry.line)
-			if err == nil {
-				if m != nil {
-					tags := m.Tags()
-					tags["path"] = entry.path
-					l.acc.AddFields(m.Name(), m.Fields(), tags, m.Time())
-				}
-			} else {
-				log.Println("E! Error parsing log line: " + err.Error())
+		m, err = l.grokParser.ParseLine(entry.line)
+		if err == nil {
+			if m != nil {
+				tags := m.Tags()
+				tags["path"] = entry.path
+				l.acc.AddFields(m.Name(), m.Fields(), tags, m.Time())
 			}
+		} else {
+			log.Println("E! Error parsing log line: " + err.Error())
 		}
 	}
 }
@@ -9,15 +9,13 @@ import (
 
 	"github.com/influxdata/telegraf/testutil"
 
-	"github.com/influxdata/telegraf/plugins/inputs/logparser/grok"
-
 	"github.com/stretchr/testify/assert"
 )
 
 func TestStartNoParsers(t *testing.T) {
 	logparser := &LogParserPlugin{
 		FromBeginning: true,
-		Files:         []string{"grok/testdata/*.log"},
+		Files:         []string{"testdata/*.log"},
 	}
 
 	acc := testutil.Accumulator{}
@@ -26,15 +24,15 @@ func TestStartNoParsers(t *testing.T) {
 
 func TestGrokParseLogFilesNonExistPattern(t *testing.T) {
 	thisdir := getCurrentDir()
-	p := &grok.Parser{
+	p := GrokConfig{
 		Patterns:           []string{"%{FOOBAR}"},
-		CustomPatternFiles: []string{thisdir + "grok/testdata/test-patterns"},
+		CustomPatternFiles: []string{thisdir + "testdata/test-patterns"},
 	}
 
 	logparser := &LogParserPlugin{
 		FromBeginning: true,
-		Files:         []string{thisdir + "grok/testdata/*.log"},
-		GrokParser:    p,
+		Files:         []string{thisdir + "testdata/*.log"},
+		GrokConfig:    p,
 	}
 
 	acc := testutil.Accumulator{}
@@ -44,15 +42,15 @@ func TestGrokParseLogFilesNonExistPattern(t *testing.T) {
 
 func TestGrokParseLogFiles(t *testing.T) {
 	thisdir := getCurrentDir()
-	p := &grok.Parser{
+	p := GrokConfig{
 		Patterns:           []string{"%{TEST_LOG_A}", "%{TEST_LOG_B}"},
-		CustomPatternFiles: []string{thisdir + "grok/testdata/test-patterns"},
+		CustomPatternFiles: []string{thisdir + "testdata/test-patterns"},
 	}
 
 	logparser := &LogParserPlugin{
 		FromBeginning: true,
-		Files:         []string{thisdir + "grok/testdata/*.log"},
-		GrokParser:    p,
+		Files:         []string{thisdir + "testdata/*.log"},
+		GrokConfig:    p,
 	}
 
 	acc := testutil.Accumulator{}
@@ -71,7 +69,7 @@ func TestGrokParseLogFiles(t *testing.T) {
 		},
 		map[string]string{
 			"response_code": "200",
-			"path":          thisdir + "grok/testdata/test_a.log",
+			"path":          thisdir + "testdata/test_a.log",
 		})
 
 	acc.AssertContainsTaggedFields(t, "logparser_grok",
@@ -81,7 +79,7 @@ func TestGrokParseLogFiles(t *testing.T) {
 			"nomodifier": "nomodifier",
 		},
 		map[string]string{
-			"path": thisdir + "grok/testdata/test_b.log",
+			"path": thisdir + "testdata/test_b.log",
 		})
 }
 
@@ -91,15 +89,15 @@ func TestGrokParseLogFilesAppearLater(t *testing.T) {
 	assert.NoError(t, err)
 
 	thisdir := getCurrentDir()
-	p := &grok.Parser{
+	p := GrokConfig{
 		Patterns:           []string{"%{TEST_LOG_A}", "%{TEST_LOG_B}"},
-		CustomPatternFiles: []string{thisdir + "grok/testdata/test-patterns"},
+		CustomPatternFiles: []string{thisdir + "testdata/test-patterns"},
 	}
 
 	logparser := &LogParserPlugin{
 		FromBeginning: true,
 		Files:         []string{emptydir + "/*.log"},
-		GrokParser:    p,
+		GrokConfig:    p,
 	}
 
 	acc := testutil.Accumulator{}
@@ -107,7 +105,7 @@ func TestGrokParseLogFilesAppearLater(t *testing.T) {
 
 	assert.Equal(t, acc.NFields(), 0)
 
-	_ = os.Symlink(thisdir+"grok/testdata/test_a.log", emptydir+"/test_a.log")
+	_ = os.Symlink(thisdir+"testdata/test_a.log", emptydir+"/test_a.log")
 	assert.NoError(t, acc.GatherError(logparser.Gather))
 	acc.Wait(1)
 
@@ -130,16 +128,15 @@ func TestGrokParseLogFilesAppearLater(t *testing.T) {
 // pattern available for test_b.log
 func TestGrokParseLogFilesOneBad(t *testing.T) {
 	thisdir := getCurrentDir()
-	p := &grok.Parser{
+	p := GrokConfig{
 		Patterns:           []string{"%{TEST_LOG_A}", "%{TEST_LOG_BAD}"},
-		CustomPatternFiles: []string{thisdir + "grok/testdata/test-patterns"},
+		CustomPatternFiles: []string{thisdir + "testdata/test-patterns"},
 	}
-	assert.NoError(t, p.Compile())
 
 	logparser := &LogParserPlugin{
 		FromBeginning: true,
-		Files:         []string{thisdir + "grok/testdata/test_a.log"},
-		GrokParser:    p,
+		Files:         []string{thisdir + "testdata/test_a.log"},
+		GrokConfig:    p,
 	}
 
 	acc := testutil.Accumulator{}
@@ -158,7 +155,7 @@ func TestGrokParseLogFilesOneBad(t *testing.T) {
 		},
 		map[string]string{
 			"response_code": "200",
-			"path":          thisdir + "grok/testdata/test_a.log",
+			"path":          thisdir + "testdata/test_a.log",
 		})
 }
 
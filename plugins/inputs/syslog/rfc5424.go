@@ -0,0 +1,316 @@
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// message is a syslog message, empty strings are nil values.
+type message struct {
+	priority       int
+	version        int
+	timestamp      time.Time
+	hostname       string
+	appname        string
+	procid         string
+	msgid          string
+	structuredData []element
+	message        string
+}
+
+// element is a structured data element.
+type element struct {
+	id     string
+	params []param
+}
+
+type param struct {
+	name  string
+	value string
+}
+
+func (m *message) facility() int {
+	return m.priority / 8
+}
+
+func (m *message) severity() int {
+	return m.priority % 8
+}
+
+var errEOF = errors.New("unexpected end of message")
+
+// scanner reads the tokens of a message.
+type scanner struct {
+	buf []byte
+	pos int
+}
+
+func (s *scanner) eof() bool {
+	return s.pos >= len(s.buf)
+}
+
+func (s *scanner) expect(c byte) error {
+	if s.eof() {
+		return errEOF
+	}
+	if s.buf[s.pos] != c {
+		return fmt.Errorf("expected %q at position %d, found %q", c, s.pos, s.buf[s.pos])
+	}
+	s.pos++
+	return nil
+}
+
+// token reads up to the next space, returning an error if the token is
+// empty or longer than max.
+func (s *scanner) token(name string, max int) (string, error) {
+	start := s.pos
+	for !s.eof() && s.buf[s.pos] != ' ' {
+		s.pos++
+	}
+	if s.pos == start {
+		return "", fmt.Errorf("missing %s at position %d", name, start)
+	}
+	if s.pos-start > max {
+		return "", fmt.Errorf("%s at position %d longer than %d", name, start, max)
+	}
+	return string(s.buf[start:s.pos]), nil
+}
+
+// priority reads the <PRI> part of a message.
+func (s *scanner) priority() (int, error) {
+	if err := s.expect('<'); err != nil {
+		return 0, err
+	}
+	start := s.pos
+	for !s.eof() && s.buf[s.pos] >= '0' && s.buf[s.pos] <= '9' && s.pos-start < 3 {
+		s.pos++
+	}
+	priority, err := strconv.Atoi(string(s.buf[start:s.pos]))
+	if err != nil || priority > 191 {
+		return 0, fmt.Errorf("invalid priority at position %d", start)
+	}
+	return priority, s.expect('>')
+}
+
+// parseRFC5424 parses a message in the format of RFC5424:
+//
+//	<PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+func parseRFC5424(buf []byte) (*message, error) {
+	s := &scanner{buf: buf}
+	m := &message{}
+
+	var err error
+	if m.priority, err = s.priority(); err != nil {
+		return nil, err
+	}
+
+	version, err := s.token("version", 2)
+	if err != nil {
+		return nil, err
+	}
+	if m.version, err = strconv.Atoi(version); err != nil || m.version == 0 {
+		return nil, fmt.Errorf("invalid version %q", version)
+	}
+	if err := s.expect(' '); err != nil {
+		return nil, err
+	}
+
+	timestamp, err := s.token("timestamp", 48)
+	if err != nil {
+		return nil, err
+	}
+	if timestamp != "-" {
+		if m.timestamp, err = time.Parse(time.RFC3339Nano, timestamp); err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q", timestamp)
+		}
+	}
+	if err := s.expect(' '); err != nil {
+		return nil, err
+	}
+
+	fields := []struct {
+		name string
+		max  int
+		dest *string
+	}{
+		{"hostname", 255, &m.hostname},
+		{"app-name", 48, &m.appname},
+		{"procid", 128, &m.procid},
+		{"msgid", 32, &m.msgid},
+	}
+	for _, f := range fields {
+		if *f.dest, err = s.token(f.name, f.max); err != nil {
+			return nil, err
+		}
+		if *f.dest == "-" {
+			*f.dest = ""
+		}
+		if err := s.expect(' '); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.structuredData, err = s.structuredData(); err != nil {
+		return nil, err
+	}
+
+	if !s.eof() {
+		if err := s.expect(' '); err != nil {
+			return nil, err
+		}
+		msg := bytes.TrimPrefix(s.buf[s.pos:], []byte("\xef\xbb\xbf"))
+		m.message = string(bytes.TrimRight(msg, "\r\n\x00"))
+	}
+	return m, nil
+}
+
+// structuredData reads the STRUCTURED-DATA part of a message, "-" or a list
+// of elements such as:
+//
+//	[id param="value" param="value"][id]
+func (s *scanner) structuredData() ([]element, error) {
+	if s.eof() {
+		return nil, errEOF
+	}
+	if s.buf[s.pos] == '-' {
+		s.pos++
+		return nil, nil
+	}
+
+	var elements []element
+	for !s.eof() && s.buf[s.pos] == '[' {
+		s.pos++
+		id, err := s.name("sd-id")
+		if err != nil {
+			return nil, err
+		}
+		e := element{id: id}
+		for {
+			if s.eof() {
+				return nil, errEOF
+			}
+			if s.buf[s.pos] == ']' {
+				s.pos++
+				break
+			}
+			if err := s.expect(' '); err != nil {
+				return nil, err
+			}
+			name, err := s.name("param-name")
+			if err != nil {
+				return nil, err
+			}
+			if err := s.expect('='); err != nil {
+				return nil, err
+			}
+			value, err := s.quoted()
+			if err != nil {
+				return nil, err
+			}
+			e.params = append(e.params, param{name: name, value: value})
+		}
+		elements = append(elements, e)
+	}
+	if len(elements) == 0 {
+		return nil, fmt.Errorf("invalid structured data at position %d", s.pos)
+	}
+	return elements, nil
+}
+
+// name reads an SD-NAME, up to 32 printable characters except '=', ' ', ']'
+// and '"'.
+func (s *scanner) name(kind string) (string, error) {
+	start := s.pos
+	for !s.eof() {
+		c := s.buf[s.pos]
+		if c <= ' ' || c > '~' || c == '=' || c == ']' || c == '"' {
+			break
+		}
+		s.pos++
+	}
+	if s.pos == start || s.pos-start > 32 {
+		return "", fmt.Errorf("invalid %s at position %d", kind, start)
+	}
+	return string(s.buf[start:s.pos]), nil
+}
+
+// quoted reads a quoted param value, unescaping '"', '\' and ']'.
+func (s *scanner) quoted() (string, error) {
+	if err := s.expect('"'); err != nil {
+		return "", err
+	}
+	var value []byte
+	for !s.eof() {
+		c := s.buf[s.pos]
+		s.pos++
+		switch c {
+		case '"':
+			return string(value), nil
+		case '\\':
+			if !s.eof() {
+				switch s.buf[s.pos] {
+				case '"', '\\', ']':
+					c = s.buf[s.pos]
+					s.pos++
+				}
+			}
+		}
+		value = append(value, c)
+	}
+	return "", errEOF
+}
+
+// parseRFC3164 parses a message in the BSD format of RFC3164, on a best
+// effort basis since the format varies between implementations:
+//
+//	<PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PROCID]: MSG
+//
+// The year of the timestamp is the current year.
+func parseRFC3164(buf []byte, now time.Time) (*message, error) {
+	s := &scanner{buf: buf}
+	m := &message{}
+
+	var err error
+	if m.priority, err = s.priority(); err != nil {
+		return nil, err
+	}
+
+	const stamp = "Jan _2 15:04:05"
+	if len(s.buf)-s.pos > len(stamp) {
+		t, err := time.ParseInLocation(stamp, string(s.buf[s.pos:s.pos+len(stamp)]), now.Location())
+		if err == nil && s.buf[s.pos+len(stamp)] == ' ' {
+			m.timestamp = t.AddDate(now.Year(), 0, 0)
+			s.pos += len(stamp) + 1
+
+			// The hostname is missing if the next token is the tag.
+			start := s.pos
+			hostname, err := s.token("hostname", 255)
+			if err == nil && !s.eof() && !bytes.ContainsAny([]byte(hostname), "[:") {
+				m.hostname = hostname
+				s.pos++
+			} else {
+				s.pos = start
+			}
+		}
+	}
+
+	// The tag is alphanumeric and terminated by '[' or ':'.
+	rest := s.buf[s.pos:]
+	if i := bytes.IndexAny(rest, "[: "); i > 0 && i <= 48 && rest[i] != ' ' {
+		m.appname = string(rest[:i])
+		rest = rest[i:]
+		if rest[0] == '[' {
+			if end := bytes.IndexByte(rest, ']'); end > 0 {
+				m.procid = string(rest[1:end])
+				rest = rest[end+1:]
+			}
+		}
+		rest = bytes.TrimPrefix(rest, []byte(":"))
+		rest = bytes.TrimPrefix(rest, []byte(" "))
+	}
+	m.message = string(bytes.TrimRight(rest, "\r\n\x00"))
+	return m, nil
+}
@@ -0,0 +1,153 @@
+package syslog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRFC5424(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *message
+	}{
+		{
+			name:  "minimal",
+			input: "<1>1 - - - - - -",
+			expected: &message{
+				priority: 1,
+				version:  1,
+			},
+		},
+		{
+			name:  "complete",
+			input: `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog 1234 ID47 [exampleSDID@32473 iut="3" eventSource="Application"][examplePriority@32473 class="high"] An application event`,
+			expected: &message{
+				priority:  165,
+				version:   1,
+				timestamp: time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC),
+				hostname:  "mymachine.example.com",
+				appname:   "evntslog",
+				procid:    "1234",
+				msgid:     "ID47",
+				structuredData: []element{
+					{
+						id: "exampleSDID@32473",
+						params: []param{
+							{name: "iut", value: "3"},
+							{name: "eventSource", value: "Application"},
+						},
+					},
+					{
+						id:     "examplePriority@32473",
+						params: []param{{name: "class", value: "high"}},
+					},
+				},
+				message: "An application event",
+			},
+		},
+		{
+			name:  "escaped param value",
+			input: `<13>1 - host app - - [id a="\"q\" \\ \]"]`,
+			expected: &message{
+				priority: 13,
+				version:  1,
+				hostname: "host",
+				appname:  "app",
+				structuredData: []element{
+					{id: "id", params: []param{{name: "a", value: `"q" \ ]`}}},
+				},
+			},
+		},
+		{
+			name:  "bom and trailing newline",
+			input: "<13>1 - - - - - - \xef\xbb\xbfhello\n",
+			expected: &message{
+				priority: 13,
+				version:  1,
+				message:  "hello",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := parseRFC5424([]byte(tt.input))
+			require.NoError(t, err)
+			if !tt.expected.timestamp.IsZero() {
+				require.True(t, tt.expected.timestamp.Equal(m.timestamp))
+				m.timestamp = tt.expected.timestamp
+			}
+			require.Equal(t, tt.expected, m)
+		})
+	}
+}
+
+func TestParseRFC5424Invalid(t *testing.T) {
+	inputs := []string{
+		"",
+		"<1>",
+		"<192>1 - - - - - -",
+		"<1>0 - - - - - -",
+		"<1>1 yesterday - - - - -",
+		"<1>1 - - - - -",
+		"<1>1 - - - - - [id",
+		`<1>1 - - - - - [id a="b]`,
+		"<1>1 - - - - - -hello",
+	}
+	for _, input := range inputs {
+		_, err := parseRFC5424([]byte(input))
+		require.Error(t, err, input)
+	}
+}
+
+func TestParseRFC3164(t *testing.T) {
+	now := time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected *message
+	}{
+		{
+			name:  "complete",
+			input: "<34>Oct 11 22:14:15 mymachine su[42]: 'su root' failed for lonvick on /dev/pts/8",
+			expected: &message{
+				priority:  34,
+				timestamp: time.Date(2018, 10, 11, 22, 14, 15, 0, time.UTC),
+				hostname:  "mymachine",
+				appname:   "su",
+				procid:    "42",
+				message:   "'su root' failed for lonvick on /dev/pts/8",
+			},
+		},
+		{
+			name:  "no hostname",
+			input: "<13>Feb  5 17:32:18 sshd: Accepted publickey",
+			expected: &message{
+				priority:  13,
+				timestamp: time.Date(2018, 2, 5, 17, 32, 18, 0, time.UTC),
+				appname:   "sshd",
+				message:   "Accepted publickey",
+			},
+		},
+		{
+			name:  "message only",
+			input: "<13>something happened",
+			expected: &message{
+				priority: 13,
+				message:  "something happened",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := parseRFC3164([]byte(tt.input), now)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, m)
+		})
+	}
+}
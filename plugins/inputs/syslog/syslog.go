@@ -0,0 +1,430 @@
+package syslog
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	tlsint "github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const (
+	defaultReadTimeout = 5 * time.Second
+	// maxMessageLength is the maximum length of a message with octet
+	// counting framing.
+	maxMessageLength = 64 * 1024
+)
+
+const sampleConfig = `
+  ## Protocol, address and port to host the syslog receiver.
+  ## If no host is specified, then localhost is used.
+  ## If no port is specified, 6514 is used (RFC5425#section-4.1).
+  server = "tcp://:6514"
+
+  ## TLS Config
+  # tls_allowed_cacerts = ["/etc/telegraf/ca.pem"]
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+
+  ## Period between keep alive probes.
+  ## 0 disables keep alive probes.
+  ## Defaults to the OS configuration.
+  ## Only applies to stream sockets (e.g. TCP).
+  # keep_alive_period = "5m"
+
+  ## Maximum number of concurrent connections (default = 0).
+  ## 0 means unlimited.
+  ## Only applies to stream sockets (e.g. TCP).
+  # max_connections = 1024
+
+  ## Read timeout is the maximum time allowed for reading a single message (default = 5s).
+  ## 0 means unlimited.
+  # read_timeout = "5s"
+
+  ## The framing technique with which it is expected that messages are
+  ## transported (default = "octet-counting").  Messages are framed by
+  ## their length with "octet-counting" (RFC5425#section-4.3.1,
+  ## RFC6587#section-3.4.1), or terminated by the trailer with
+  ## "non-transparent" (RFC6587#section-3.4.2).
+  ## Only applies to stream sockets (e.g. TCP).
+  # framing = "octet-counting"
+
+  ## The trailer of messages with non-transparent framing, "LF" or "NUL"
+  ## (default = "LF").
+  # trailer = "LF"
+
+  ## Parse messages not following RFC5424 in the BSD format of RFC3164
+  ## (default = false).
+  # best_effort = false
+
+  ## Character to join the SD-ID and the SD-PARAM names of the fields of the
+  ## structured data (default = "_").
+  # sdparam_separator = "_"
+`
+
+var severityNames = []string{
+	"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug",
+}
+
+var facilityNames = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console",
+	"solaris-cron", "local0", "local1", "local2", "local3", "local4",
+	"local5", "local6", "local7",
+}
+
+// Syslog is a syslog receiver.
+type Syslog struct {
+	Address         string             `toml:"server"`
+	KeepAlivePeriod *internal.Duration `toml:"keep_alive_period"`
+	MaxConnections  int                `toml:"max_connections"`
+	ReadTimeout     *internal.Duration `toml:"read_timeout"`
+	Framing         string             `toml:"framing"`
+	Trailer         string             `toml:"trailer"`
+	BestEffort      bool               `toml:"best_effort"`
+	Separator       string             `toml:"sdparam_separator"`
+	tlsint.ServerConfig
+
+	now func() time.Time
+
+	mu          sync.Mutex
+	wg          sync.WaitGroup
+	acc         telegraf.Accumulator
+	listener    net.Listener
+	packetConn  net.PacketConn
+	connections map[string]net.Conn
+}
+
+func (s *Syslog) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Syslog) Description() string {
+	return "Accepts syslog messages following RFC5424 format with transports as per RFC5426, RFC5425, or RFC6587"
+}
+
+func (s *Syslog) Gather(_ telegraf.Accumulator) error {
+	return nil
+}
+
+func (s *Syslog) Start(acc telegraf.Accumulator) error {
+	scheme, host, err := getAddressParts(s.Address)
+	if err != nil {
+		return err
+	}
+
+	switch s.Framing {
+	case "", "octet-counting", "non-transparent":
+	default:
+		return fmt.Errorf("invalid framing %q", s.Framing)
+	}
+	switch strings.ToUpper(s.Trailer) {
+	case "", "LF", "NUL":
+	default:
+		return fmt.Errorf("invalid trailer %q", s.Trailer)
+	}
+	if s.Separator == "" {
+		s.Separator = "_"
+	}
+	if s.now == nil {
+		s.now = time.Now
+	}
+
+	s.acc = acc
+	s.connections = make(map[string]net.Conn)
+
+	switch scheme {
+	case "tcp", "tcp4", "tcp6":
+		tlsCfg, err := s.ServerConfig.TLSConfig()
+		if err != nil {
+			return err
+		}
+		if tlsCfg == nil {
+			s.listener, err = net.Listen(scheme, host)
+		} else {
+			s.listener, err = tls.Listen(scheme, host, tlsCfg)
+		}
+		if err != nil {
+			return err
+		}
+
+		s.wg.Add(1)
+		go s.listenStream()
+	case "udp", "udp4", "udp6":
+		s.packetConn, err = net.ListenPacket(scheme, host)
+		if err != nil {
+			return err
+		}
+
+		s.wg.Add(1)
+		go s.listenPacket()
+	default:
+		return fmt.Errorf("unknown protocol %q in %q", scheme, s.Address)
+	}
+	return nil
+}
+
+func (s *Syslog) Stop() {
+	s.mu.Lock()
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	if s.packetConn != nil {
+		s.packetConn.Close()
+	}
+	for _, c := range s.connections {
+		c.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// addr returns the address the receiver listens on.
+func (s *Syslog) addr() net.Addr {
+	if s.listener != nil {
+		return s.listener.Addr()
+	}
+	return s.packetConn.LocalAddr()
+}
+
+// getAddressParts returns the scheme and host of the address, adding the
+// default port if missing.
+func getAddressParts(a string) (string, string, error) {
+	parts := strings.SplitN(a, "://", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("missing protocol within address '%s'", a)
+	}
+
+	host := parts[1]
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(strings.Trim(host, "[]"), "6514")
+	}
+	return parts[0], host, nil
+}
+
+func (s *Syslog) listenPacket() {
+	defer s.wg.Done()
+
+	buf := make([]byte, 64*1024) // 64kb - maximum size of IP packet
+	for {
+		n, addr, err := s.packetConn.ReadFrom(buf)
+		if err != nil {
+			if !strings.HasSuffix(err.Error(), ": use of closed network connection") {
+				s.acc.AddError(err)
+			}
+			return
+		}
+		s.store(buf[:n], addr)
+	}
+}
+
+func (s *Syslog) listenStream() {
+	defer s.wg.Done()
+
+	for {
+		c, err := s.listener.Accept()
+		if err != nil {
+			if !strings.HasSuffix(err.Error(), ": use of closed network connection") {
+				s.acc.AddError(err)
+			}
+			return
+		}
+
+		s.mu.Lock()
+		if s.MaxConnections > 0 && len(s.connections) >= s.MaxConnections {
+			s.mu.Unlock()
+			c.Close()
+			continue
+		}
+		s.connections[c.RemoteAddr().String()] = c
+		s.mu.Unlock()
+
+		if err := s.setKeepAlive(c); err != nil {
+			s.acc.AddError(fmt.Errorf("unable to configure keep alive (%s): %s", s.Address, err))
+		}
+
+		s.wg.Add(1)
+		go s.handle(c)
+	}
+}
+
+func (s *Syslog) setKeepAlive(c net.Conn) error {
+	if s.KeepAlivePeriod == nil {
+		return nil
+	}
+	tcpc, ok := c.(*net.TCPConn)
+	if !ok {
+		return fmt.Errorf("cannot set keep alive on a %s socket", c.LocalAddr().Network())
+	}
+	if s.KeepAlivePeriod.Duration == 0 {
+		return tcpc.SetKeepAlive(false)
+	}
+	if err := tcpc.SetKeepAlive(true); err != nil {
+		return err
+	}
+	return tcpc.SetKeepAlivePeriod(s.KeepAlivePeriod.Duration)
+}
+
+// handle reads the framed messages of a connection.
+func (s *Syslog) handle(c net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.connections, c.RemoteAddr().String())
+		s.mu.Unlock()
+		c.Close()
+	}()
+
+	timeout := defaultReadTimeout
+	if s.ReadTimeout != nil {
+		timeout = s.ReadTimeout.Duration
+	}
+
+	r := bufio.NewReader(c)
+	for {
+		if timeout > 0 {
+			c.SetReadDeadline(time.Now().Add(timeout))
+		}
+
+		var buf []byte
+		var err error
+		if s.Framing == "non-transparent" {
+			buf, err = readNonTransparent(r, s.trailer())
+		} else {
+			buf, err = readOctetCounting(r)
+		}
+		if len(buf) > 0 {
+			s.store(buf, c.RemoteAddr())
+		}
+		if err != nil {
+			if err == io.EOF || strings.HasSuffix(err.Error(), ": use of closed network connection") {
+				return
+			}
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				return
+			}
+			s.acc.AddError(err)
+			return
+		}
+	}
+}
+
+func (s *Syslog) trailer() byte {
+	if strings.ToUpper(s.Trailer) == "NUL" {
+		return 0
+	}
+	return '\n'
+}
+
+// readOctetCounting reads a message framed as "MSG-LEN SP SYSLOG-MSG".
+func readOctetCounting(r *bufio.Reader) ([]byte, error) {
+	length, err := r.ReadString(' ')
+	if err != nil {
+		if err == io.EOF && length != "" {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+	if err != nil || n <= 0 || n > maxMessageLength {
+		return nil, fmt.Errorf("invalid message length %q", strings.TrimSpace(length))
+	}
+
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// readNonTransparent reads a message terminated by the trailer, the last
+// message may be unterminated.
+func readNonTransparent(r *bufio.Reader, trailer byte) ([]byte, error) {
+	buf, err := r.ReadBytes(trailer)
+	if len(buf) > 0 && buf[len(buf)-1] == trailer {
+		buf = buf[:len(buf)-1]
+	}
+	if trailer == '\n' && len(buf) > 0 && buf[len(buf)-1] == '\r' {
+		buf = buf[:len(buf)-1]
+	}
+	return buf, err
+}
+
+func (s *Syslog) store(buf []byte, addr net.Addr) {
+	now := s.now()
+	m, err := parseRFC5424(buf)
+	if err != nil && s.BestEffort {
+		m, err = parseRFC3164(buf, now)
+	}
+	if err != nil {
+		s.acc.AddError(fmt.Errorf("unable to parse message: %s", err))
+		return
+	}
+
+	tags, fields := s.tagsAndFields(m)
+	if addr != nil {
+		if host, _, err := net.SplitHostPort(addr.String()); err == nil {
+			tags["source"] = host
+		}
+	}
+	s.acc.AddFields("syslog", fields, tags, now)
+}
+
+func (s *Syslog) tagsAndFields(m *message) (map[string]string, map[string]interface{}) {
+	tags := map[string]string{
+		"severity": severityNames[m.severity()],
+		"facility": facilityNames[m.facility()],
+	}
+	if m.hostname != "" {
+		tags["hostname"] = m.hostname
+	}
+	if m.appname != "" {
+		tags["appname"] = m.appname
+	}
+
+	fields := map[string]interface{}{
+		"severity_code": m.severity(),
+		"facility_code": m.facility(),
+	}
+	if m.version != 0 {
+		fields["version"] = m.version
+	}
+	if !m.timestamp.IsZero() {
+		fields["timestamp"] = m.timestamp.UnixNano()
+	}
+	if m.procid != "" {
+		fields["procid"] = m.procid
+	}
+	if m.msgid != "" {
+		fields["msgid"] = m.msgid
+	}
+	if m.message != "" {
+		fields["message"] = m.message
+	}
+	for _, e := range m.structuredData {
+		fields[e.id] = true
+		for _, p := range e.params {
+			fields[e.id+s.Separator+p.name] = p.value
+		}
+	}
+	return tags, fields
+}
+
+func init() {
+	inputs.Add("syslog", func() telegraf.Input {
+		return &Syslog{
+			Framing:   "octet-counting",
+			Trailer:   "LF",
+			Separator: "_",
+		}
+	})
+}
@@ -0,0 +1,219 @@
+package syslog
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+var pki = testutil.NewPKI("../../../testutil/pki")
+
+var defaultTime = time.Unix(0, 0)
+
+const testMessage = `<29>1 2016-02-21T04:32:57.000Z web1 someservice 2341 2 [origin][meta sequence="14125553" service="someservice"] "GET /v1/ok HTTP/1.1" 200 145`
+
+func newTestSyslog(address string) *Syslog {
+	return &Syslog{
+		Address:   address,
+		Framing:   "octet-counting",
+		Trailer:   "LF",
+		Separator: "_",
+		now: func() time.Time {
+			return defaultTime
+		},
+	}
+}
+
+func assertTestMessage(t *testing.T, acc *testutil.Accumulator) {
+	acc.AssertContainsTaggedFields(t, "syslog",
+		map[string]interface{}{
+			"version":       1,
+			"severity_code": 5,
+			"facility_code": 3,
+			"timestamp":     time.Unix(1456029177, 0).UnixNano(),
+			"procid":        "2341",
+			"msgid":         "2",
+			"message":       `"GET /v1/ok HTTP/1.1" 200 145`,
+			"origin":        true,
+			"meta":          true,
+			"meta_sequence": "14125553",
+			"meta_service":  "someservice",
+		},
+		map[string]string{
+			"severity": "notice",
+			"facility": "daemon",
+			"hostname": "web1",
+			"appname":  "someservice",
+			"source":   "127.0.0.1",
+		},
+	)
+}
+
+func TestStreamOctetCounting(t *testing.T) {
+	s := newTestSyslog("tcp://127.0.0.1:0")
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	conn, err := net.Dial("tcp", s.addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = fmt.Fprintf(conn, "%d %s%d %s", len(testMessage), testMessage, len(testMessage), testMessage)
+	require.NoError(t, err)
+
+	acc.Wait(2)
+	assertTestMessage(t, acc)
+}
+
+func TestStreamNonTransparent(t *testing.T) {
+	for _, trailer := range []string{"LF", "NUL"} {
+		t.Run(trailer, func(t *testing.T) {
+			s := newTestSyslog("tcp://127.0.0.1:0")
+			s.Framing = "non-transparent"
+			s.Trailer = trailer
+			acc := &testutil.Accumulator{}
+			require.NoError(t, s.Start(acc))
+			defer s.Stop()
+
+			conn, err := net.Dial("tcp", s.addr().String())
+			require.NoError(t, err)
+			defer conn.Close()
+
+			end := "\n"
+			if trailer == "NUL" {
+				end = "\x00"
+			}
+			_, err = fmt.Fprint(conn, testMessage+end+testMessage+end)
+			require.NoError(t, err)
+
+			acc.Wait(2)
+			assertTestMessage(t, acc)
+		})
+	}
+}
+
+func TestStreamTLS(t *testing.T) {
+	s := newTestSyslog("tcp://127.0.0.1:0")
+	s.ServerConfig = *pki.TLSServerConfig()
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	tlsCfg, err := pki.TLSClientConfig().TLSConfig()
+	require.NoError(t, err)
+
+	conn, err := tls.Dial("tcp", s.addr().String(), tlsCfg)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = fmt.Fprintf(conn, "%d %s", len(testMessage), testMessage)
+	require.NoError(t, err)
+
+	acc.Wait(1)
+	assertTestMessage(t, acc)
+}
+
+func TestStreamInvalidLength(t *testing.T) {
+	s := newTestSyslog("tcp://127.0.0.1:0")
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	conn, err := net.Dial("tcp", s.addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = fmt.Fprint(conn, "abc "+testMessage)
+	require.NoError(t, err)
+
+	acc.WaitError(1)
+	require.Contains(t, acc.Errors[0].Error(), "invalid message length")
+}
+
+func TestPacket(t *testing.T) {
+	s := newTestSyslog("udp://127.0.0.1:0")
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	conn, err := net.Dial("udp", s.addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(testMessage))
+	require.NoError(t, err)
+
+	acc.Wait(1)
+	assertTestMessage(t, acc)
+}
+
+const bsdMessage = "<34>Oct 11 22:14:15 mymachine su: 'su root' failed"
+
+func TestPacketStrict(t *testing.T) {
+	s := newTestSyslog("udp://127.0.0.1:0")
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	conn, err := net.Dial("udp", s.addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(bsdMessage))
+	require.NoError(t, err)
+
+	acc.WaitError(1)
+	require.Equal(t, uint64(0), acc.NMetrics())
+}
+
+func TestPacketBestEffort(t *testing.T) {
+	s := newTestSyslog("udp://127.0.0.1:0")
+	s.BestEffort = true
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	conn, err := net.Dial("udp", s.addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(bsdMessage))
+	require.NoError(t, err)
+	acc.Wait(1)
+
+	acc.AssertContainsTaggedFields(t, "syslog",
+		map[string]interface{}{
+			"severity_code": 2,
+			"facility_code": 4,
+			"timestamp":     time.Date(1970, 10, 11, 22, 14, 15, 0, time.Local).UnixNano(),
+			"message":       "'su root' failed",
+		},
+		map[string]string{
+			"severity": "crit",
+			"facility": "auth",
+			"hostname": "mymachine",
+			"appname":  "su",
+			"source":   "127.0.0.1",
+		},
+	)
+}
+
+func TestAddressParts(t *testing.T) {
+	scheme, host, err := getAddressParts("tcp://localhost")
+	require.NoError(t, err)
+	require.Equal(t, "tcp", scheme)
+	require.Equal(t, "localhost:6514", host)
+
+	_, host, err = getAddressParts("udp://[::1]:514")
+	require.NoError(t, err)
+	require.Equal(t, "[::1]:514", host)
+
+	_, _, err = getAddressParts("localhost:514")
+	require.Error(t, err)
+}
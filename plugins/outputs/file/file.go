@@ -3,25 +3,69 @@ package file
 import (
 	"fmt"
 	"io"
+	"log"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/rotate"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
 )
 
+// idleTimeout is the time after which files of templated paths are closed
+// if no metrics are written to them.
+const idleTimeout = 10 * time.Minute
+
 type File struct {
-	Files []string
+	Files               []string
+	RotationInterval    internal.Duration `toml:"rotation_interval"`
+	RotationMaxSize     internal.Size     `toml:"rotation_max_size"`
+	RotationMaxArchives int               `toml:"rotation_max_archives"`
+	RotationCompress    bool              `toml:"rotation_compress"`
+	UseBatchFormat      bool              `toml:"use_batch_format"`
 
 	writers []io.Writer
 	closers []io.Closer
 
+	templates []*pathTemplate
+	routed    map[string]*routedFile
+
 	serializer serializers.Serializer
 }
 
+// routedFile is an open file of a templated path.
+type routedFile struct {
+	writer   *rotate.FileWriter
+	lastUsed time.Time
+}
+
 var sampleConfig = `
-  ## Files to write to, "stdout" is a specially handled file.
+  ## Files to write to, "stdout" is a specially handled file.  Paths may
+  ## contain Go templates using the metric name and tags, and strftime
+  ## directives using the metric time, to write metrics to separate files.
   files = ["stdout", "/tmp/metrics.out"]
+  # files = ['/var/metrics/{{.Name}}/{{.Tag "host"}}-%Y-%m-%d.lp']
+
+  ## Rotate the files when they become older than the interval, or larger
+  ## than the size.  Rotated files are renamed with a timestamp before the
+  ## extension.  Set to 0 to disable rotation.
+  # rotation_interval = "0h"
+  # rotation_max_size = "0MB"
+
+  ## Maximum number of rotated files to keep per file, older files are
+  ## removed.  Set to -1 to keep all rotated files.
+  # rotation_max_archives = 5
+
+  ## Compress rotated files with gzip.
+  # rotation_compress = false
+
+  ## Use batch serialization format instead of line based delimiting.  The
+  ## batch format allows for the production of non line based output formats
+  ## and may more efficiently encode metric groups.
+  # use_batch_format = false
 
   ## Data format to output.
   ## Each data format has its own unique set of configuration options, read
@@ -39,18 +83,18 @@ func (f *File) Connect() error {
 		f.Files = []string{"stdout"}
 	}
 
+	f.routed = make(map[string]*routedFile)
 	for _, file := range f.Files {
 		if file == "stdout" {
 			f.writers = append(f.writers, os.Stdout)
-		} else {
-			var of *os.File
-			var err error
-			if _, err := os.Stat(file); os.IsNotExist(err) {
-				of, err = os.Create(file)
-			} else {
-				of, err = os.OpenFile(file, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
+		} else if isTemplate(file) {
+			tmpl, err := newPathTemplate(file)
+			if err != nil {
+				return err
 			}
-
+			f.templates = append(f.templates, tmpl)
+		} else {
+			of, err := f.openFile(file)
 			if err != nil {
 				return err
 			}
@@ -61,6 +105,14 @@ func (f *File) Connect() error {
 	return nil
 }
 
+func (f *File) openFile(file string) (*rotate.FileWriter, error) {
+	return rotate.NewFileWriter(file,
+		f.RotationInterval.Duration,
+		f.RotationMaxSize.Size,
+		f.RotationMaxArchives,
+		f.RotationCompress)
+}
+
 func (f *File) Close() error {
 	var errS string
 	for _, c := range f.closers {
@@ -68,6 +120,12 @@ func (f *File) Close() error {
 			errS += err.Error() + "\n"
 		}
 	}
+	for path, routed := range f.routed {
+		if err := routed.writer.Close(); err != nil {
+			errS += err.Error() + "\n"
+		}
+		delete(f.routed, path)
+	}
 	if errS != "" {
 		return fmt.Errorf(errS)
 	}
@@ -87,6 +145,55 @@ func (f *File) Write(metrics []telegraf.Metric) error {
 		return nil
 	}
 
+	var writeErr error = nil
+	if len(f.writers) > 0 {
+		if err := f.writeMetrics(f.writers, metrics); err != nil {
+			writeErr = err
+		}
+	}
+
+	for _, tmpl := range f.templates {
+		var paths []string
+		routes := make(map[string][]telegraf.Metric)
+		for _, metric := range metrics {
+			path, err := tmpl.render(metric)
+			if err != nil {
+				log.Printf("E! [outputs.file] dropping metric, failed to render file path: %s", err)
+				continue
+			}
+			if _, ok := routes[path]; !ok {
+				paths = append(paths, path)
+			}
+			routes[path] = append(routes[path], metric)
+		}
+
+		for _, path := range paths {
+			writer, err := f.routedWriter(path)
+			if err != nil {
+				writeErr = fmt.Errorf("E! failed to open file: %s", err)
+				continue
+			}
+			if err := f.writeMetrics([]io.Writer{writer}, routes[path]); err != nil {
+				writeErr = err
+			}
+		}
+	}
+
+	f.closeIdle()
+	return writeErr
+}
+
+// writeMetrics serializes the metrics and writes them to the writers, errors
+// writing to stdout are ignored.
+func (f *File) writeMetrics(writers []io.Writer, metrics []telegraf.Metric) error {
+	if f.UseBatchFormat {
+		b, err := f.serializer.SerializeBatch(metrics)
+		if err != nil {
+			return fmt.Errorf("failed to serialize message: %s", err)
+		}
+		return write(writers, b)
+	}
+
 	var writeErr error = nil
 	for _, metric := range metrics {
 		b, err := f.serializer.Serialize(metric)
@@ -94,18 +201,58 @@ func (f *File) Write(metrics []telegraf.Metric) error {
 			return fmt.Errorf("failed to serialize message: %s", err)
 		}
 
-		for _, writer := range f.writers {
-			_, err = writer.Write(b)
-			if err != nil && writer != os.Stdout {
-				writeErr = fmt.Errorf("E! failed to write message: %s, %s", b, err)
-			}
+		if err := write(writers, b); err != nil {
+			writeErr = err
 		}
 	}
 	return writeErr
 }
 
+func write(writers []io.Writer, b []byte) error {
+	var writeErr error = nil
+	for _, writer := range writers {
+		_, err := writer.Write(b)
+		if err != nil && writer != os.Stdout {
+			writeErr = fmt.Errorf("E! failed to write message: %s, %s", b, err)
+		}
+	}
+	return writeErr
+}
+
+// routedWriter returns the writer of a templated path, creating the file and
+// its directory if needed.
+func (f *File) routedWriter(path string) (io.Writer, error) {
+	routed, ok := f.routed[path]
+	if !ok {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+		writer, err := f.openFile(path)
+		if err != nil {
+			return nil, err
+		}
+		routed = &routedFile{writer: writer}
+		f.routed[path] = routed
+	}
+	routed.lastUsed = time.Now()
+	return routed.writer, nil
+}
+
+// closeIdle closes the files of templated paths not written to recently, so
+// that paths containing dates don't leak open files.
+func (f *File) closeIdle() {
+	for path, routed := range f.routed {
+		if time.Since(routed.lastUsed) > idleTimeout {
+			routed.writer.Close()
+			delete(f.routed, path)
+		}
+	}
+}
+
 func init() {
 	outputs.Add("file", func() telegraf.Output {
-		return &File{}
+		return &File{
+			RotationMaxArchives: 5,
+		}
 	})
 }
@@ -5,10 +5,14 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/testutil"
@@ -109,6 +113,149 @@ func TestFileNewFiles(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestFileBatchFormat(t *testing.T) {
+	s, _ := serializers.NewSerializer(&serializers.Config{
+		DataFormat:      "json",
+		JSONBatchFormat: "array",
+	})
+	fh := tmpFile()
+	f := File{
+		Files:          []string{fh},
+		UseBatchFormat: true,
+		serializer:     s,
+	}
+
+	err := f.Connect()
+	assert.NoError(t, err)
+
+	err = f.Write(testutil.MockMetrics())
+	assert.NoError(t, err)
+
+	validateFile(fh, `[{"fields":{"value":1},"name":"test1","tags":{"tag1":"value1"},"timestamp":1257894000}]`, t)
+
+	err = f.Close()
+	assert.NoError(t, err)
+}
+
+func TestFileTemplatedPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, _ := serializers.NewInfluxSerializer()
+	f := File{
+		Files:      []string{filepath.Join(dir, `{{.Name}}/{{.Tag "host"}}-%Y-%m-%d.lp`)},
+		serializer: s,
+	}
+
+	err = f.Connect()
+	require.NoError(t, err)
+
+	now := time.Date(2018, 6, 24, 10, 0, 0, 0, time.UTC)
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": int64(1)}, now),
+		testutil.MustMetric("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": int64(2)}, now),
+		testutil.MustMetric("mem", map[string]string{"host": "a"}, map[string]interface{}{"value": int64(3)}, now),
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": int64(4)}, now.Add(24*time.Hour)),
+	}
+	err = f.Write(metrics)
+	require.NoError(t, err)
+
+	err = f.Close()
+	require.NoError(t, err)
+
+	validateFile(filepath.Join(dir, "cpu", "a-2018-06-24.lp"), "cpu,host=a value=1i 1529834400000000000\n", t)
+	validateFile(filepath.Join(dir, "cpu", "b-2018-06-24.lp"), "cpu,host=b value=2i 1529834400000000000\n", t)
+	validateFile(filepath.Join(dir, "mem", "a-2018-06-24.lp"), "mem,host=a value=3i 1529834400000000000\n", t)
+	validateFile(filepath.Join(dir, "cpu", "a-2018-06-25.lp"), "cpu,host=a value=4i 1529920800000000000\n", t)
+}
+
+func TestFileInvalidTemplatedPath(t *testing.T) {
+	f := File{
+		Files: []string{"/tmp/{{.Name}.lp"},
+	}
+	require.Error(t, f.Connect())
+}
+
+func TestFileTemplatedPathTraversal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, _ := serializers.NewInfluxSerializer()
+	f := File{
+		Files:      []string{filepath.Join(dir, "metrics", `{{.Tag "host"}}.lp`)},
+		serializer: s,
+	}
+
+	err = f.Connect()
+	require.NoError(t, err)
+
+	now := time.Date(2018, 6, 24, 10, 0, 0, 0, time.UTC)
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "../escaped"}, map[string]interface{}{"value": int64(1)}, now),
+		testutil.MustMetric("cpu", map[string]string{"host": "a/../../escaped"}, map[string]interface{}{"value": int64(2)}, now),
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": int64(3)}, now),
+	}
+	err = f.Write(metrics)
+	require.NoError(t, err)
+
+	err = f.Close()
+	require.NoError(t, err)
+
+	validateFile(filepath.Join(dir, "metrics", "a.lp"), "cpu,host=a value=3i 1529834400000000000\n", t)
+	_, err = os.Stat(filepath.Join(dir, "escaped.lp"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestIsTemplate(t *testing.T) {
+	assert.True(t, isTemplate("/var/metrics/{{.Name}}.lp"))
+	assert.True(t, isTemplate("/var/metrics/%Y-%m-%d.lp"))
+	assert.True(t, isTemplate("/var/metrics/100%%.lp"))
+	assert.False(t, isTemplate("/data/100%.out"))
+	assert.False(t, isTemplate("/data/%Q.out"))
+}
+
+func TestPathTemplateLiteralPercent(t *testing.T) {
+	tmpl, err := newPathTemplate("/data/%Q-%Y-100%")
+	require.NoError(t, err)
+
+	m := testutil.MustMetric("cpu", nil, map[string]interface{}{"value": 1.0}, time.Date(2018, 6, 24, 10, 0, 0, 0, time.UTC))
+	path, err := tmpl.render(m)
+	require.NoError(t, err)
+	assert.Equal(t, "/data/%Q-2018-100%", path)
+}
+
+func TestFileRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, _ := serializers.NewInfluxSerializer()
+	f := File{
+		Files:               []string{filepath.Join(dir, "metrics.out")},
+		RotationMaxSize:     internal.Size{Size: 50},
+		RotationMaxArchives: 1,
+		serializer:          s,
+	}
+
+	err = f.Connect()
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		err = f.Write(testutil.MockMetrics())
+		require.NoError(t, err)
+	}
+
+	err = f.Close()
+	require.NoError(t, err)
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	validateFile(filepath.Join(dir, "metrics.out"), expNewFile, t)
+}
+
 func TestFileBoth(t *testing.T) {
 	fh1 := createFile()
 	fh2 := tmpFile()
@@ -0,0 +1,115 @@
+package file
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/influxdata/telegraf"
+	tmpldata "github.com/influxdata/telegraf/plugins/serializers/template"
+)
+
+// strftimeLayouts maps strftime directives to Go time layouts.
+var strftimeLayouts = map[byte]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'd': "02",
+	'H': "15",
+	'M': "04",
+	'S': "05",
+	'b': "Jan",
+	'a': "Mon",
+}
+
+// pathTemplate is a file path rendered for each metric, using a Go template
+// and strftime directives formatted with the metric time, for example
+// "/var/metrics/{{.Name}}/%Y-%m-%d.lp".
+type pathTemplate struct {
+	tmpl *template.Template
+	// dir is the static directory of the path, rendered paths must stay
+	// inside of it.
+	dir string
+}
+
+// isTemplate returns true if the path has to be rendered per metric, that is
+// if it contains a Go template action or a supported strftime directive.
+func isTemplate(path string) bool {
+	return strings.Contains(path, "{{") || directive(path) >= 0
+}
+
+// directive returns the index of the first supported strftime directive, or
+// of a %% escape, in the path, or -1 if there is none.
+func directive(path string) int {
+	for i := 0; i < len(path)-1; i++ {
+		if path[i] != '%' {
+			continue
+		}
+		if _, ok := strftimeLayouts[path[i+1]]; ok || path[i+1] == '%' {
+			return i
+		}
+	}
+	return -1
+}
+
+func newPathTemplate(path string) (*pathTemplate, error) {
+	var text bytes.Buffer
+	for i := 0; i < len(path); i++ {
+		if path[i] != '%' || i == len(path)-1 {
+			text.WriteByte(path[i])
+			continue
+		}
+
+		// Unsupported directives are kept as is.
+		i++
+		if path[i] == '%' {
+			text.WriteString(`{{"%"}}`)
+			continue
+		}
+		layout, ok := strftimeLayouts[path[i]]
+		if !ok {
+			text.WriteByte('%')
+			text.WriteByte(path[i])
+			continue
+		}
+		fmt.Fprintf(&text, "{{.Time.Format %q}}", layout)
+	}
+
+	tmpl, err := template.New("path").Parse(text.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid file path %q: %v", path, err)
+	}
+	return &pathTemplate{tmpl: tmpl, dir: staticDir(path)}, nil
+}
+
+// staticDir returns the directory of the part of the path before the first
+// template action or directive.
+func staticDir(path string) string {
+	static := path
+	if i := strings.Index(static, "{{"); i >= 0 {
+		static = static[:i]
+	}
+	if i := directive(static); i >= 0 {
+		static = static[:i]
+	}
+	static = static[:strings.LastIndexAny(static, "/"+string(filepath.Separator))+1]
+	return filepath.Clean(static)
+}
+
+// render returns the path of the metric.  Paths leaving the static directory
+// of the template, through tag values such as "../..", are rejected.
+func (p *pathTemplate) render(metric telegraf.Metric) (string, error) {
+	var buf bytes.Buffer
+	if err := p.tmpl.Execute(&buf, tmpldata.NewMetric(metric)); err != nil {
+		return "", err
+	}
+
+	path := filepath.Clean(buf.String())
+	rel, err := filepath.Rel(p.dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %q is outside of %q", buf.String(), p.dir)
+	}
+	return path, nil
+}
@@ -1,8 +1,10 @@
 package graphite
 
 import (
+	"bytes"
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"math/rand"
@@ -22,8 +24,21 @@ type Graphite struct {
 	Prefix   string
 	Template string
 	Timeout  int
-	conns    []net.Conn
+	// Protocol is "plaintext" or "pickle"
+	Protocol string `toml:"protocol"`
+	// ServerSelection is "random", "round_robin" or "consistent_hash"
+	ServerSelection string `toml:"server_selection"`
 	tlsint.ClientConfig
+
+	// conns holds the connection of each server, nil if not connected
+	conns     []net.Conn
+	tlsConfig *tls.Config
+	ring      *hashRing
+	next      int
+	// sent are the lines delivered by the consistent_hash selection before
+	// the route of another server failed, they are skipped when the batch
+	// is written again.
+	sent map[string]bool
 }
 
 var sampleConfig = `
@@ -31,6 +46,21 @@ var sampleConfig = `
   ## If multiple endpoints are configured, output will be load balanced.
   ## Only one of the endpoints will be written to with each iteration.
   servers = ["localhost:2003"]
+
+  ## Selection of the server of each write, the next servers are tried if
+  ## it fails:
+  ##   random          - a random server
+  ##   round_robin     - the servers in turn
+  ##   consistent_hash - the server of each metric path on a hash ring, so
+  ##                     that a path is sent to the same server while it
+  ##                     is up; when it fails the path is sent to the next
+  ##                     servers instead
+  # server_selection = "random"
+
+  ## Protocol of the servers, "plaintext" or "pickle".  Pickle sends
+  ## batches of at most 500 datapoints.
+  # protocol = "plaintext"
+
   ## Prefix metrics name
   prefix = ""
   ## Graphite output template
@@ -60,38 +90,61 @@ func (g *Graphite) Connect() error {
 		g.Servers = append(g.Servers, "localhost:2003")
 	}
 
+	switch g.Protocol {
+	case "", "plaintext", "pickle":
+	default:
+		return fmt.Errorf("invalid protocol: %s", g.Protocol)
+	}
+	switch g.ServerSelection {
+	case "", "random", "round_robin":
+	case "consistent_hash":
+		g.ring = newHashRing(g.Servers)
+	default:
+		return fmt.Errorf("invalid server_selection: %s", g.ServerSelection)
+	}
+
 	// Set tls config
 	tlsConfig, err := g.ClientConfig.TLSConfig()
 	if err != nil {
 		return err
 	}
+	g.tlsConfig = tlsConfig
 
-	// Get Connections
-	var conns []net.Conn
-	for _, server := range g.Servers {
-		// Dialer with timeout
-		d := net.Dialer{Timeout: time.Duration(g.Timeout) * time.Second}
+	// Get Connections, failed servers are reconnected on write
+	g.conns = make([]net.Conn, len(g.Servers))
+	for n := range g.Servers {
+		g.dial(n)
+	}
+	return nil
+}
 
-		// Get secure connection if tls config is set
-		var conn net.Conn
-		if tlsConfig != nil {
-			conn, err = tls.DialWithDialer(&d, "tcp", server, tlsConfig)
-		} else {
-			conn, err = d.Dial("tcp", server)
-		}
+// dial connects to the server n.
+func (g *Graphite) dial(n int) (net.Conn, error) {
+	// Dialer with timeout
+	d := net.Dialer{Timeout: time.Duration(g.Timeout) * time.Second}
 
-		if err == nil {
-			conns = append(conns, conn)
-		}
+	// Get secure connection if tls config is set
+	var conn net.Conn
+	var err error
+	if g.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(&d, "tcp", g.Servers[n], g.tlsConfig)
+	} else {
+		conn, err = d.Dial("tcp", g.Servers[n])
 	}
-	g.conns = conns
-	return nil
+	if err != nil {
+		return nil, err
+	}
+	g.conns[n] = conn
+	return conn, nil
 }
 
 func (g *Graphite) Close() error {
 	// Closing all connections
-	for _, conn := range g.conns {
-		conn.Close()
+	for n, conn := range g.conns {
+		if conn != nil {
+			conn.Close()
+			g.conns[n] = nil
+		}
 	}
 	return nil
 }
@@ -109,14 +162,14 @@ func (g *Graphite) Description() string {
 // We can detect that by finding an eof
 // if not for this, we can happily write and flush without getting errors (in Go) but getting RST tcp packets back (!)
 // props to Tv via the authors of carbon-relay-ng` for this trick.
-func checkEOF(conn net.Conn) {
+func checkEOF(conn net.Conn) error {
 	b := make([]byte, 1024)
 	conn.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
 	num, err := conn.Read(b)
 	if err == io.EOF {
 		log.Printf("E! Conn %s is closed. closing conn explicitly", conn)
 		conn.Close()
-		return
+		return err
 	}
 	// just in case i misunderstand something or the remote behaves badly
 	if num != 0 {
@@ -126,14 +179,16 @@ func checkEOF(conn net.Conn) {
 	if e, ok := err.(net.Error); !(ok && e.Timeout()) {
 		log.Printf("E! conn %s checkEOF .conn.Read returned err != EOF, which is unexpected.  closing conn. error: %s\n", conn, err)
 		conn.Close()
+		return err
 	}
+	return nil
 }
 
-// Choose a random server in the cluster to write to until a successful write
+// Choose a server in the cluster to write to until a successful write
 // occurs, logging each unsuccessful. If all servers fail, return error.
 func (g *Graphite) Write(metrics []telegraf.Metric) error {
 	// Prepare data
-	var batch []byte
+	var lines [][]byte
 	s, err := serializers.NewGraphiteSerializer(g.Prefix, g.Template, g.GraphiteTagSupport)
 	if err != nil {
 		return err
@@ -144,37 +199,108 @@ func (g *Graphite) Write(metrics []telegraf.Metric) error {
 		if err != nil {
 			log.Printf("E! Error serializing some metrics to graphite: %s", err.Error())
 		}
-		batch = append(batch, buf...)
+		for _, line := range bytes.SplitAfter(buf, []byte("\n")) {
+			if len(line) > 0 {
+				lines = append(lines, line)
+			}
+		}
 	}
 
-	err = g.send(batch)
+	if g.ring == nil {
+		return g.write(g.order(), lines)
+	}
+
+	// Send the lines of each server of the ring, skipping the lines already
+	// delivered when a previous write of the batch failed
+	var servers []int
+	routes := make(map[int][][]byte)
+	for _, line := range lines {
+		if g.sent[string(line)] {
+			continue
+		}
+		path := line
+		if i := bytes.IndexByte(line, ' '); i >= 0 {
+			path = line[:i]
+		}
+		n := g.ring.server(string(path))
+		if _, ok := routes[n]; !ok {
+			servers = append(servers, n)
+		}
+		routes[n] = append(routes[n], line)
+	}
+
+	// All routes are written even if one fails, the error is returned once
+	// the delivered lines are recorded so only the failed routes are resent
+	var failed error
+	var delivered [][]byte
+	for _, n := range servers {
+		if err := g.write(g.failover(n), routes[n]); err != nil {
+			failed = err
+			continue
+		}
+		delivered = append(delivered, routes[n]...)
+	}
+	if failed != nil {
+		if g.sent == nil {
+			g.sent = make(map[string]bool)
+		}
+		for _, line := range delivered {
+			g.sent[string(line)] = true
+		}
+		return failed
+	}
+	g.sent = nil
+	return nil
+}
+
+// order returns the servers to try for a write.
+func (g *Graphite) order() []int {
+	if g.ServerSelection == "round_robin" {
+		n := g.next % len(g.conns)
+		g.next = n + 1
+		return g.failover(n)
+	}
+	return rand.Perm(len(g.conns))
+}
+
+// failover returns the server n followed by the other servers in order.
+func (g *Graphite) failover(n int) []int {
+	order := make([]int, 0, len(g.conns))
+	for i := range g.conns {
+		order = append(order, (n+i)%len(g.conns))
+	}
+	return order
+}
+
+// write encodes the lines and sends them to the first of the servers
+// accepting them.
+func (g *Graphite) write(order []int, lines [][]byte) error {
+	var batches [][]byte
+	if g.Protocol == "pickle" {
+		batches = pickleBatches(lines)
+	} else {
+		batches = [][]byte{bytes.Join(lines, nil)}
+	}
+
+	err := g.send(order, batches)
 
 	// try to reconnect and retry to send
 	if err != nil {
 		log.Println("E! Graphite: Reconnecting and retrying: ")
-		g.Connect()
-		err = g.send(batch)
+		err = g.send(order, batches)
 	}
 
 	return err
 }
 
-func (g *Graphite) send(batch []byte) error {
+func (g *Graphite) send(order []int, batches [][]byte) error {
 	// This will get set to nil if a successful write occurs
 	err := errors.New("Could not write to any Graphite server in cluster\n")
 
-	// Send data to a random server
-	p := rand.Perm(len(g.conns))
-	for _, n := range p {
-		if g.Timeout > 0 {
-			g.conns[n].SetWriteDeadline(time.Now().Add(time.Duration(g.Timeout) * time.Second))
-		}
-		checkEOF(g.conns[n])
-		if _, e := g.conns[n].Write(batch); e != nil {
+	for _, n := range order {
+		if e := g.sendTo(n, batches); e != nil {
 			// Error
 			log.Println("E! Graphite Error: " + e.Error())
-			// Close explicitely
-			g.conns[n].Close()
 			// Let's try the next one
 		} else {
 			// Success
@@ -186,6 +312,35 @@ func (g *Graphite) send(batch []byte) error {
 	return err
 }
 
+// sendTo writes the batches to the server n, connecting to it if needed.
+// The connection is closed on errors and reopened on the next write.
+func (g *Graphite) sendTo(n int, batches [][]byte) error {
+	conn := g.conns[n]
+	if conn == nil {
+		var err error
+		conn, err = g.dial(n)
+		if err != nil {
+			return err
+		}
+	} else if err := checkEOF(conn); err != nil {
+		g.conns[n] = nil
+		return err
+	}
+
+	if g.Timeout > 0 {
+		conn.SetWriteDeadline(time.Now().Add(time.Duration(g.Timeout) * time.Second))
+	}
+	for _, batch := range batches {
+		if _, err := conn.Write(batch); err != nil {
+			// Close explicitely
+			conn.Close()
+			g.conns[n] = nil
+			return err
+		}
+	}
+	return nil
+}
+
 func init() {
 	outputs.Add("graphite", func() telegraf.Output {
 		return &Graphite{}
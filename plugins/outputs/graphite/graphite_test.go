@@ -2,14 +2,18 @@ package graphite
 
 import (
 	"bufio"
+	"bytes"
+	"fmt"
 	"net"
 	"net/textproto"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -217,3 +221,193 @@ func TCPServer2WithTags(t *testing.T, wg *sync.WaitGroup) {
 		tcpServer.Close()
 	}()
 }
+
+// testServer accepts connections and records the data received from them.
+type testServer struct {
+	net.Listener
+	mu   sync.Mutex
+	data bytes.Buffer
+	wg   sync.WaitGroup
+}
+
+func newTestServer(t *testing.T) *testServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &testServer{Listener: l}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				buf := make([]byte, 1024)
+				for {
+					n, err := conn.Read(buf)
+					s.mu.Lock()
+					s.data.Write(buf[:n])
+					s.mu.Unlock()
+					if err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return s
+}
+
+// received closes the server and returns the data received.
+func (s *testServer) received() string {
+	s.Close()
+	s.wg.Wait()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.String()
+}
+
+func testMetric(name string) telegraf.Metric {
+	return testutil.MustMetric(
+		name,
+		map[string]string{"host": "192.168.0.1"},
+		map[string]interface{}{"value": float64(3.14)},
+		time.Date(2010, time.November, 10, 23, 0, 0, 0, time.UTC),
+	)
+}
+
+func TestGraphiteRoundRobin(t *testing.T) {
+	s1 := newTestServer(t)
+	s2 := newTestServer(t)
+
+	g := Graphite{
+		Servers:         []string{s1.Addr().String(), s2.Addr().String()},
+		ServerSelection: "round_robin",
+	}
+	require.NoError(t, g.Connect())
+	require.NoError(t, g.Write([]telegraf.Metric{testMetric("first")}))
+	require.NoError(t, g.Write([]telegraf.Metric{testMetric("second")}))
+	require.NoError(t, g.Write([]telegraf.Metric{testMetric("third")}))
+	require.NoError(t, g.Close())
+
+	assert.Equal(t, "192_168_0_1.first 3.14 1289430000\n192_168_0_1.third 3.14 1289430000\n", s1.received())
+	assert.Equal(t, "192_168_0_1.second 3.14 1289430000\n", s2.received())
+}
+
+func TestGraphiteConsistentHash(t *testing.T) {
+	s1 := newTestServer(t)
+	s2 := newTestServer(t)
+	servers := []string{s1.Addr().String(), s2.Addr().String()}
+
+	g := Graphite{
+		Servers:         servers,
+		ServerSelection: "consistent_hash",
+	}
+	require.NoError(t, g.Connect())
+
+	var metrics []telegraf.Metric
+	expected := make([]string, 2)
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("m%d", i)
+		metrics = append(metrics, testMetric(name))
+		path := "192_168_0_1." + name
+		n := g.ring.server(path)
+		expected[n] += path + " 3.14 1289430000\n"
+	}
+	require.NoError(t, g.Write(metrics))
+	require.NoError(t, g.Close())
+
+	assert.NotEmpty(t, expected[0])
+	assert.NotEmpty(t, expected[1])
+	assert.Equal(t, expected[0], s1.received())
+	assert.Equal(t, expected[1], s2.received())
+
+	// the server of a path does not depend on the other servers
+	ring := newHashRing([]string{servers[0], servers[1], "127.0.0.1:1"})
+	for i := 0; i < 20; i++ {
+		path := fmt.Sprintf("192_168_0_1.m%d", i)
+		if n := ring.server(path); n != 2 {
+			assert.Equal(t, g.ring.server(path), n)
+		}
+	}
+}
+
+func TestGraphiteConsistentHashSkipsSent(t *testing.T) {
+	s := newTestServer(t)
+
+	g := Graphite{
+		Servers:         []string{s.Addr().String()},
+		ServerSelection: "consistent_hash",
+		sent: map[string]bool{
+			"192_168_0_1.first 3.14 1289430000\n": true,
+		},
+	}
+	require.NoError(t, g.Connect())
+	require.NoError(t, g.Write([]telegraf.Metric{testMetric("first"), testMetric("second")}))
+	assert.Nil(t, g.sent)
+
+	require.NoError(t, g.Write([]telegraf.Metric{testMetric("first")}))
+	require.NoError(t, g.Close())
+
+	assert.Equal(t, "192_168_0_1.second 3.14 1289430000\n192_168_0_1.first 3.14 1289430000\n", s.received())
+}
+
+func TestGraphiteConsistentHashFailover(t *testing.T) {
+	s := newTestServer(t)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	down := l.Addr().String()
+	l.Close()
+
+	g := Graphite{
+		Servers:         []string{s.Addr().String(), down},
+		ServerSelection: "consistent_hash",
+	}
+	require.NoError(t, g.Connect())
+
+	// the paths of the server that is down fail over to the other server
+	var metrics []telegraf.Metric
+	var moved int
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("m%d", i)
+		metrics = append(metrics, testMetric(name))
+		if g.ring.server("192_168_0_1."+name) == 1 {
+			moved++
+		}
+	}
+	require.NoError(t, g.Write(metrics))
+	assert.Nil(t, g.sent)
+	require.NoError(t, g.Close())
+
+	assert.NotZero(t, moved)
+	assert.Equal(t, 20, strings.Count(s.received(), "\n"))
+}
+
+func TestGraphitePickle(t *testing.T) {
+	s := newTestServer(t)
+
+	g := Graphite{
+		Servers:  []string{s.Addr().String()},
+		Protocol: "pickle",
+	}
+	require.NoError(t, g.Connect())
+	require.NoError(t, g.Write([]telegraf.Metric{testMetric("cpu"), testMetric("mem")}))
+	require.NoError(t, g.Close())
+
+	points, err := unpickle(strings.NewReader(s.received()))
+	require.NoError(t, err)
+	assert.Equal(t, []datapoint{
+		{path: "192_168_0_1.cpu", value: 3.14, timestamp: 1289430000},
+		{path: "192_168_0_1.mem", value: 3.14, timestamp: 1289430000},
+	}, points)
+}
+
+func TestGraphiteInvalidConfig(t *testing.T) {
+	g := Graphite{Protocol: "json"}
+	require.Error(t, g.Connect())
+
+	g = Graphite{ServerSelection: "first"}
+	require.Error(t, g.Connect())
+}
@@ -0,0 +1,104 @@
+package graphite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxPickleBatch is the maximum number of datapoints of a pickle message.
+const maxPickleBatch = 500
+
+// Opcodes of the pickle protocol 2.
+const (
+	pickleProto      = 0x80
+	pickleEmptyList  = ']'
+	pickleMark       = '('
+	pickleAppends    = 'e'
+	pickleBinUnicode = 'X'
+	pickleBinFloat   = 'G'
+	pickleBinInt     = 'J'
+	pickleLong1      = 0x8a
+	pickleTuple2     = 0x86
+	pickleStop       = '.'
+)
+
+// datapoint is a parsed line of the plaintext protocol.
+type datapoint struct {
+	path      string
+	value     float64
+	timestamp int64
+}
+
+func parseLine(line []byte) (datapoint, error) {
+	parts := strings.Fields(string(line))
+	if len(parts) != 3 {
+		return datapoint{}, fmt.Errorf("invalid line %q", line)
+	}
+	value, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return datapoint{}, fmt.Errorf("invalid value of line %q", line)
+	}
+	timestamp, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return datapoint{}, fmt.Errorf("invalid timestamp of line %q", line)
+	}
+	return datapoint{path: parts[0], value: value, timestamp: timestamp}, nil
+}
+
+// pickleBatches encodes the lines as messages of the pickle protocol, each
+// a 4 byte length followed by a pickled list of
+// (path, (timestamp, value)) tuples.  Invalid lines are skipped.
+func pickleBatches(lines [][]byte) [][]byte {
+	var batches [][]byte
+	var points []datapoint
+	for _, line := range lines {
+		dp, err := parseLine(line)
+		if err != nil {
+			log.Printf("E! Graphite: skipping %s", err)
+			continue
+		}
+		points = append(points, dp)
+		if len(points) == maxPickleBatch {
+			batches = append(batches, pickle(points))
+			points = points[:0]
+		}
+	}
+	if len(points) > 0 {
+		batches = append(batches, pickle(points))
+	}
+	return batches
+}
+
+func pickle(points []datapoint) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{0, 0, 0, 0})
+	buf.Write([]byte{pickleProto, 2, pickleEmptyList, pickleMark})
+	for _, dp := range points {
+		buf.WriteByte(pickleBinUnicode)
+		binary.Write(&buf, binary.LittleEndian, uint32(len(dp.path)))
+		buf.WriteString(dp.path)
+
+		if dp.timestamp >= math.MinInt32 && dp.timestamp <= math.MaxInt32 {
+			buf.WriteByte(pickleBinInt)
+			binary.Write(&buf, binary.LittleEndian, int32(dp.timestamp))
+		} else {
+			buf.Write([]byte{pickleLong1, 8})
+			binary.Write(&buf, binary.LittleEndian, dp.timestamp)
+		}
+
+		buf.WriteByte(pickleBinFloat)
+		binary.Write(&buf, binary.BigEndian, dp.value)
+
+		buf.Write([]byte{pickleTuple2, pickleTuple2})
+	}
+	buf.Write([]byte{pickleAppends, pickleStop})
+
+	b := buf.Bytes()
+	binary.BigEndian.PutUint32(b, uint32(len(b)-4))
+	return b
+}
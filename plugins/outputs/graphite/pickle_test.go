@@ -0,0 +1,115 @@
+package graphite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// unpickle decodes a pickle message as written by pickle.
+func unpickle(r io.Reader) ([]datapoint, error) {
+	var length uint32
+	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+
+	b := bytes.NewReader(buf)
+	header := make([]byte, 4)
+	b.Read(header)
+	if !bytes.Equal(header, []byte{pickleProto, 2, pickleEmptyList, pickleMark}) {
+		return nil, fmt.Errorf("invalid header %v", header)
+	}
+
+	var points []datapoint
+	for {
+		op, err := b.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if op == pickleAppends {
+			break
+		}
+		if op != pickleBinUnicode {
+			return nil, fmt.Errorf("unexpected opcode %x", op)
+		}
+
+		var dp datapoint
+		var n uint32
+		binary.Read(b, binary.LittleEndian, &n)
+		path := make([]byte, n)
+		b.Read(path)
+		dp.path = string(path)
+
+		op, _ = b.ReadByte()
+		switch op {
+		case pickleBinInt:
+			var ts int32
+			binary.Read(b, binary.LittleEndian, &ts)
+			dp.timestamp = int64(ts)
+		case pickleLong1:
+			b.ReadByte()
+			binary.Read(b, binary.LittleEndian, &dp.timestamp)
+		default:
+			return nil, fmt.Errorf("unexpected opcode %x", op)
+		}
+
+		if op, _ = b.ReadByte(); op != pickleBinFloat {
+			return nil, fmt.Errorf("unexpected opcode %x", op)
+		}
+		binary.Read(b, binary.BigEndian, &dp.value)
+
+		tuples := make([]byte, 2)
+		b.Read(tuples)
+		if !bytes.Equal(tuples, []byte{pickleTuple2, pickleTuple2}) {
+			return nil, fmt.Errorf("invalid tuples %v", tuples)
+		}
+		points = append(points, dp)
+	}
+
+	if op, _ := b.ReadByte(); op != pickleStop || b.Len() != 0 {
+		return nil, fmt.Errorf("invalid end of message")
+	}
+	return points, nil
+}
+
+func TestPickle(t *testing.T) {
+	lines := [][]byte{
+		[]byte("my.prefix.cpu.usage 3.14 1289430000\n"),
+		[]byte("my.prefix.cpu.idle;host=a -1 4294967296\n"),
+		[]byte("my.prefix.cpu.invalid abc 1289430000\n"),
+	}
+
+	batches := pickleBatches(lines)
+	require.Len(t, batches, 1)
+
+	points, err := unpickle(bytes.NewReader(batches[0]))
+	require.NoError(t, err)
+	require.Equal(t, []datapoint{
+		{path: "my.prefix.cpu.usage", value: 3.14, timestamp: 1289430000},
+		{path: "my.prefix.cpu.idle;host=a", value: -1, timestamp: 4294967296},
+	}, points)
+}
+
+func TestPickleBatches(t *testing.T) {
+	var lines [][]byte
+	for i := 0; i < maxPickleBatch+1; i++ {
+		lines = append(lines, []byte(fmt.Sprintf("cpu.%d %d 1289430000\n", i, i)))
+	}
+
+	batches := pickleBatches(lines)
+	require.Len(t, batches, 2)
+
+	points, err := unpickle(bytes.NewReader(batches[1]))
+	require.NoError(t, err)
+	require.Equal(t, []datapoint{
+		{path: fmt.Sprintf("cpu.%d", maxPickleBatch), value: maxPickleBatch, timestamp: 1289430000},
+	}, points)
+}
@@ -0,0 +1,56 @@
+package graphite
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"sort"
+	"strconv"
+)
+
+// ringReplicas is the number of points of each server on the hash ring.
+const ringReplicas = 100
+
+type ringPoint struct {
+	hash   uint32
+	server int
+}
+
+// hashRing maps metric paths to servers by consistent hashing, so that
+// adding or removing a server only moves the paths of its neighbours.
+type hashRing struct {
+	points []ringPoint
+}
+
+func newHashRing(servers []string) *hashRing {
+	r := &hashRing{}
+	for i, server := range servers {
+		for j := 0; j < ringReplicas; j++ {
+			r.points = append(r.points, ringPoint{
+				hash:   hash(server + ":" + strconv.Itoa(j)),
+				server: i,
+			})
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		return r.points[i].hash < r.points[j].hash
+	})
+	return r
+}
+
+// server returns the index of the server of the path.
+func (r *hashRing) server(path string) int {
+	h := hash(path)
+	i := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i].hash >= h
+	})
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.points[i].server
+}
+
+// hash returns the first 4 bytes of the md5 sum, as carbon does.
+func hash(s string) uint32 {
+	sum := md5.Sum([]byte(s))
+	return binary.BigEndian.Uint32(sum[:4])
+}
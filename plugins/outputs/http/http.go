@@ -2,6 +2,7 @@ package http
 
 import (
 	"bytes"
+	"compress/gzip"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/httppush"
 	"github.com/influxdata/telegraf/internal/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
@@ -29,6 +31,21 @@ var sampleConfig = `
   # username = "username"
   # password = "pa$$word"
 
+  ## OAuth2 Client Credentials Grant
+  ## A token is requested from the token_url and sent as a bearer token, it
+  ## is cached until it expires or is rejected by the server.
+  # client_id = "clientid"
+  # client_secret = "secret"
+  # token_url = "https://identityprovider/oauth2/v1/token"
+  # scopes = ["urn:opc:idm:__myscopes__"]
+
+  ## HTTP Content-Encoding for write request body, can be set to "gzip" to
+  ## compress body or "identity" to apply no encoding.
+  # content_encoding = "identity"
+
+  ## Status codes of successful writes, by default any 2xx status code.
+  # success_status_codes = [200, 204]
+
   ## Additional HTTP headers
   # [outputs.http.headers]
   #   # Should be set to "application/json" for json data_format
@@ -55,16 +72,23 @@ const (
 )
 
 type HTTP struct {
-	URL      string            `toml:"url"`
-	Timeout  internal.Duration `toml:"timeout"`
-	Method   string            `toml:"method"`
-	Username string            `toml:"username"`
-	Password string            `toml:"password"`
-	Headers  map[string]string `toml:"headers"`
+	URL                string            `toml:"url"`
+	Timeout            internal.Duration `toml:"timeout"`
+	Method             string            `toml:"method"`
+	Username           string            `toml:"username"`
+	Password           string            `toml:"password"`
+	Headers            map[string]string `toml:"headers"`
+	ClientID           string            `toml:"client_id"`
+	ClientSecret       string            `toml:"client_secret"`
+	TokenURL           string            `toml:"token_url"`
+	Scopes             []string          `toml:"scopes"`
+	ContentEncoding    string            `toml:"content_encoding"`
+	SuccessStatusCodes []int             `toml:"success_status_codes"`
 	tls.ClientConfig
 
-	client     *http.Client
-	serializer serializers.Serializer
+	client      *http.Client
+	credentials *clientCredentials
+	serializer  serializers.Serializer
 }
 
 func (h *HTTP) SetSerializer(serializer serializers.Serializer) {
@@ -84,6 +108,19 @@ func (h *HTTP) Connect() error {
 		h.Timeout.Duration = defaultClientTimeout
 	}
 
+	switch h.ContentEncoding {
+	case "", "identity", "gzip":
+	default:
+		return fmt.Errorf("invalid content_encoding [%s] %s", h.URL, h.ContentEncoding)
+	}
+
+	if h.ClientID != "" || h.ClientSecret != "" || h.TokenURL != "" {
+		if h.ClientID == "" || h.ClientSecret == "" || h.TokenURL == "" {
+			return fmt.Errorf("client_id, client_secret and token_url are required for OAuth2")
+		}
+		h.credentials = newClientCredentials(h.ClientID, h.ClientSecret, h.TokenURL, h.Scopes)
+	}
+
 	tlsCfg, err := h.ClientConfig.TLSConfig()
 	if err != nil {
 		return err
@@ -126,27 +163,74 @@ func (h *HTTP) Write(metrics []telegraf.Metric) error {
 }
 
 func (h *HTTP) write(reqBody []byte) error {
-	req, err := http.NewRequest(h.Method, h.URL, bytes.NewBuffer(reqBody))
+	var body bytes.Buffer
+	if h.ContentEncoding == "gzip" {
+		gz := gzip.NewWriter(&body)
+		if _, err := gz.Write(reqBody); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+	} else {
+		body.Write(reqBody)
+	}
+
+	req, err := http.NewRequest(h.Method, h.URL, &body)
+	if err != nil {
+		return err
+	}
 
 	req.Header.Set("Content-Type", defaultContentType)
+	if h.ContentEncoding == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
 	for k, v := range h.Headers {
 		req.Header.Set(k, v)
 	}
 
+	if h.credentials != nil {
+		token, err := h.credentials.Token(h.client)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
 	resp, err := h.client.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
-	_, err = ioutil.ReadAll(resp.Body)
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("when writing to [%s] received status code: %d", h.URL, resp.StatusCode)
+	if !h.isSuccess(resp.StatusCode) {
+		if resp.StatusCode == http.StatusUnauthorized && h.credentials != nil {
+			h.credentials.Invalidate()
+		}
+		return httppush.StatusError(req, resp.StatusCode, respBody)
 	}
 
 	return nil
 }
 
+// isSuccess returns true if the status code is one of the success status
+// codes, or any 2xx status code if none are set.
+func (h *HTTP) isSuccess(statusCode int) bool {
+	if len(h.SuccessStatusCodes) == 0 {
+		return statusCode >= 200 && statusCode < 300
+	}
+	for _, code := range h.SuccessStatusCodes {
+		if statusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
 func init() {
 	outputs.Add("http", func() telegraf.Output {
 		return &HTTP{
@@ -1,10 +1,13 @@
 package http
 
 import (
+	"compress/gzip"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -289,3 +292,140 @@ func TestBasicAuth(t *testing.T) {
 		})
 	}
 }
+
+func TestSuccessStatusCodes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("queued"))
+	}))
+	defer ts.Close()
+
+	plugin := &HTTP{
+		URL:                ts.URL,
+		SuccessStatusCodes: []int{http.StatusOK},
+	}
+	plugin.SetSerializer(influx.NewSerializer())
+	require.NoError(t, plugin.Connect())
+
+	err := plugin.Write([]telegraf.Metric{getMetric()})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "received status code: 202, body: queued")
+
+	plugin.SuccessStatusCodes = []int{http.StatusOK, http.StatusAccepted}
+	require.NoError(t, plugin.Write([]telegraf.Metric{getMetric()}))
+}
+
+func TestContentEncodingGzip(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+
+		gr, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(gr)
+		require.NoError(t, err)
+		require.Equal(t, "cpu value=42 0\n", string(body))
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	plugin := &HTTP{
+		URL:             ts.URL,
+		ContentEncoding: "gzip",
+	}
+	plugin.SetSerializer(influx.NewSerializer())
+	require.NoError(t, plugin.Connect())
+
+	err := plugin.Write([]telegraf.Metric{getMetric()})
+	require.NoError(t, err)
+}
+
+func TestInvalidContentEncoding(t *testing.T) {
+	plugin := &HTTP{
+		URL:             "http://localhost",
+		ContentEncoding: "deflate",
+	}
+	require.Error(t, plugin.Connect())
+}
+
+func TestOAuthClientCredentials(t *testing.T) {
+	var tokens int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+			require.Equal(t, "read write", r.PostForm.Get("scope"))
+			username, password, _ := r.BasicAuth()
+			require.Equal(t, "howdy", username)
+			require.Equal(t, "secret", password)
+
+			n := atomic.AddInt32(&tokens, 1)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"access_token":"token%d","token_type":"bearer","expires_in":3600}`, n)
+		case "/write":
+			if r.Header.Get("Authorization") != "Bearer token2" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer ts.Close()
+
+	plugin := &HTTP{
+		URL:          ts.URL + "/write",
+		ClientID:     "howdy",
+		ClientSecret: "secret",
+		TokenURL:     ts.URL + "/token",
+		Scopes:       []string{"read", "write"},
+	}
+	plugin.SetSerializer(influx.NewSerializer())
+	require.NoError(t, plugin.Connect())
+
+	// the first token is rejected and discarded
+	err := plugin.Write([]telegraf.Metric{getMetric()})
+	require.Error(t, err)
+
+	// the second token is cached
+	require.NoError(t, plugin.Write([]telegraf.Metric{getMetric()}))
+	require.NoError(t, plugin.Write([]telegraf.Metric{getMetric()}))
+	require.Equal(t, int32(2), atomic.LoadInt32(&tokens))
+}
+
+func TestOAuthTokenExpiry(t *testing.T) {
+	var tokens int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&tokens, 1)
+		fmt.Fprintf(w, `{"access_token":"token%d","expires_in":60}`, n)
+	}))
+	defer ts.Close()
+
+	now := time.Unix(0, 0)
+	c := newClientCredentials("howdy", "secret", ts.URL, nil)
+	c.now = func() time.Time {
+		return now
+	}
+
+	token, err := c.Token(http.DefaultClient)
+	require.NoError(t, err)
+	require.Equal(t, "token1", token)
+
+	now = now.Add(45 * time.Second)
+	token, err = c.Token(http.DefaultClient)
+	require.NoError(t, err)
+	require.Equal(t, "token1", token)
+
+	now = now.Add(10 * time.Second)
+	token, err = c.Token(http.DefaultClient)
+	require.NoError(t, err)
+	require.Equal(t, "token2", token)
+}
+
+func TestOAuthMissingConfig(t *testing.T) {
+	plugin := &HTTP{
+		URL:      "http://localhost",
+		ClientID: "howdy",
+	}
+	require.Error(t, plugin.Connect())
+}
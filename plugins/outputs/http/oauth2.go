@@ -0,0 +1,109 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf/internal/httppush"
+)
+
+// expiryDelta is subtracted from the expiry of tokens, so that tokens are
+// refreshed before they expire during a request.
+const expiryDelta = 10 * time.Second
+
+// clientCredentials acquires access tokens with the OAuth2 client credentials
+// grant, caching each token until it expires.
+type clientCredentials struct {
+	clientID     string
+	clientSecret string
+	tokenURL     string
+	scopes       []string
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+	now    func() time.Time
+}
+
+func newClientCredentials(clientID, clientSecret, tokenURL string, scopes []string) *clientCredentials {
+	return &clientCredentials{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		tokenURL:     tokenURL,
+		scopes:       scopes,
+		now:          time.Now,
+	}
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Token returns the cached token, requesting a new token from the token URL
+// if there is none or it has expired.
+func (c *clientCredentials) Token(client *http.Client) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && (c.expiry.IsZero() || c.now().Before(c.expiry)) {
+		return c.token, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(c.scopes) > 0 {
+		form.Set("scope", strings.Join(c.scopes, " "))
+	}
+	req, err := http.NewRequest(http.MethodPost, c.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(c.clientID), url.QueryEscape(c.clientSecret))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("requesting token from [%s]: %s", c.tokenURL, err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading token from [%s]: %s", c.tokenURL, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("requesting token from [%s] received status code: %d, body: %s",
+			c.tokenURL, resp.StatusCode, httppush.ErrorBody(body))
+	}
+
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("decoding token from [%s]: %s", c.tokenURL, err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("token response from [%s] has no access_token", c.tokenURL)
+	}
+	if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+		return "", fmt.Errorf("unsupported token type %q from [%s]", token.TokenType, c.tokenURL)
+	}
+
+	c.token = token.AccessToken
+	c.expiry = time.Time{}
+	if token.ExpiresIn > 0 {
+		c.expiry = c.now().Add(time.Duration(token.ExpiresIn)*time.Second - expiryDelta)
+	}
+	return c.token, nil
+}
+
+// Invalidate discards the cached token, used when the token is rejected.
+func (c *clientCredentials) Invalidate() {
+	c.mu.Lock()
+	c.token = ""
+	c.mu.Unlock()
+}
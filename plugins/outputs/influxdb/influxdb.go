@@ -22,6 +22,16 @@ var (
 	ErrMissingURL = errors.New("missing URL")
 )
 
+const (
+	// WriteModeAny writes each batch to one of the URLs, trying the others
+	// if the write fails.
+	WriteModeAny = "any"
+	// WriteModeAll writes each batch to all URLs.
+	WriteModeAll = "all"
+
+	defaultURLBufferLimit = 10000
+)
+
 type Client interface {
 	Write(context.Context, []telegraf.Metric) error
 	CreateDatabase(ctx context.Context) error
@@ -47,11 +57,14 @@ type InfluxDB struct {
 	ContentEncoding      string            `toml:"content_encoding"`
 	SkipDatabaseCreation bool              `toml:"skip_database_creation"`
 	InfluxUintSupport    bool              `toml:"influx_uint_support"`
+	WriteMode            string            `toml:"write_mode"`
+	URLBufferLimit       int               `toml:"url_buffer_limit"`
 	tls.ClientConfig
 
 	Precision string // precision deprecated in 1.0; value is ignored
 
-	clients []Client
+	clients  []Client
+	replicas []*replica
 
 	CreateHTTPClientF func(config *HTTPConfig) (Client, error)
 	CreateUDPClientF  func(config *UDPConfig) (Client, error)
@@ -63,11 +76,19 @@ var sampleConfig = `
   ## The full HTTP or UDP URL for your InfluxDB instance.
   ##
   ## Multiple URLs can be specified for a single cluster, only ONE of the
-  ## urls will be written to each interval.
+  ## urls will be written to each interval, unless write_mode is "all".
   # urls = ["unix:///var/run/influxdb.sock"]
   # urls = ["udp://127.0.0.1:8089"]
   # urls = ["http://127.0.0.1:8086"]
 
+  ## How metrics are written when multiple URLs are specified, can be:
+  ##   "any": write each batch to one url, trying the others on failure.
+  ##   "all": write each batch to every url.  Each url has its own buffer of
+  ##          up to url_buffer_limit metrics, failed writes are retried in
+  ##          the background without blocking writes to the other urls.
+  # write_mode = "any"
+  # url_buffer_limit = 10000
+
   ## The target database for metrics; will be created as needed.
   # database = "telegraf"
 
@@ -135,6 +156,17 @@ func (i *InfluxDB) Connect() error {
 		urls = append(urls, defaultURL)
 	}
 
+	switch i.WriteMode {
+	case "":
+		i.WriteMode = WriteModeAny
+	case WriteModeAny, WriteModeAll:
+	default:
+		return fmt.Errorf("invalid write_mode: %q", i.WriteMode)
+	}
+	if i.URLBufferLimit <= 0 {
+		i.URLBufferLimit = defaultURLBufferLimit
+	}
+
 	i.serializer = influx.NewSerializer()
 	if i.InfluxUintSupport {
 		i.serializer.SetFieldTypeSupport(influx.UintSupport)
@@ -174,10 +206,22 @@ func (i *InfluxDB) Connect() error {
 		}
 	}
 
+	if i.WriteMode == WriteModeAll {
+		for _, c := range i.clients {
+			r := newReplica(c, i.URLBufferLimit, i.handleError)
+			r.start()
+			i.replicas = append(i.replicas, r)
+		}
+	}
+
 	return nil
 }
 
 func (i *InfluxDB) Close() error {
+	for _, r := range i.replicas {
+		r.stop()
+	}
+	i.replicas = nil
 	return nil
 }
 
@@ -190,8 +234,16 @@ func (i *InfluxDB) SampleConfig() string {
 }
 
 // Write sends metrics to one of the configured servers, logging each
-// unsuccessful. If all servers fail, return an error.
+// unsuccessful. If all servers fail, return an error.  When writing to all
+// servers the metrics are added to the buffer of each server instead.
 func (i *InfluxDB) Write(metrics []telegraf.Metric) error {
+	if i.WriteMode == WriteModeAll {
+		for _, r := range i.replicas {
+			r.add(metrics)
+		}
+		return nil
+	}
+
 	ctx := context.Background()
 
 	var err error
@@ -203,23 +255,29 @@ func (i *InfluxDB) Write(metrics []telegraf.Metric) error {
 			return nil
 		}
 
-		switch apiError := err.(type) {
-		case *APIError:
-			if !i.SkipDatabaseCreation {
-				if apiError.Type == DatabaseNotFound {
-					err := client.CreateDatabase(ctx)
-					if err != nil {
-						log.Printf("E! [outputs.influxdb] when writing to [%s]: database %q not found and failed to recreate",
-							client.URL(), client.Database())
-					}
+		i.handleError(ctx, client, err)
+	}
+
+	return errors.New("could not write any address")
+}
+
+// handleError logs a failed write, creating the database if the write failed
+// because it doesn't exist.
+func (i *InfluxDB) handleError(ctx context.Context, client Client, err error) {
+	switch apiError := err.(type) {
+	case *APIError:
+		if !i.SkipDatabaseCreation {
+			if apiError.Type == DatabaseNotFound {
+				err := client.CreateDatabase(ctx)
+				if err != nil {
+					log.Printf("E! [outputs.influxdb] when writing to [%s]: database %q not found and failed to recreate",
+						client.URL(), client.Database())
 				}
 			}
 		}
-
-		log.Printf("E! [outputs.influxdb]: when writing to [%s]: %v", client.URL(), err)
 	}
 
-	return errors.New("could not write any address")
+	log.Printf("E! [outputs.influxdb]: when writing to [%s]: %v", client.URL(), err)
 }
 
 func (i *InfluxDB) udpClient(url *url.URL) (Client, error) {
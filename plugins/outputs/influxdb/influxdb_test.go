@@ -3,6 +3,7 @@ package influxdb_test
 import (
 	"context"
 	"net/http"
+	"sync"
 	"testing"
 	"time"
 
@@ -182,3 +183,68 @@ func TestWriteRecreateDatabaseIfDatabaseNotFound(t *testing.T) {
 	// We only have one URL, so we expect an error
 	require.Error(t, err)
 }
+
+func TestWriteModeAll(t *testing.T) {
+	var mu sync.Mutex
+	written := make(map[string]int)
+	newClient := func(url string) influxdb.Client {
+		return &MockClient{
+			URLF: func() string {
+				return url
+			},
+			DatabaseF: func() string {
+				return "telegraf"
+			},
+			CreateDatabaseF: func(ctx context.Context) error {
+				return nil
+			},
+			WriteF: func(ctx context.Context, metrics []telegraf.Metric) error {
+				mu.Lock()
+				defer mu.Unlock()
+				written[url] += len(metrics)
+				return nil
+			},
+		}
+	}
+
+	output := influxdb.InfluxDB{
+		URLs:      []string{"http://localhost:8086", "http://localhost:9086"},
+		WriteMode: "all",
+
+		CreateHTTPClientF: func(config *influxdb.HTTPConfig) (influxdb.Client, error) {
+			return newClient(config.URL.String()), nil
+		},
+	}
+
+	err := output.Connect()
+	require.NoError(t, err)
+
+	m, err := metric.New(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{
+			"value": 42.0,
+		},
+		time.Unix(0, 0),
+	)
+	require.NoError(t, err)
+
+	err = output.Write([]telegraf.Metric{m})
+	require.NoError(t, err)
+
+	require.NoError(t, output.Close())
+	require.Equal(t, map[string]int{
+		"http://localhost:8086": 1,
+		"http://localhost:9086": 1,
+	}, written)
+}
+
+func TestInvalidWriteMode(t *testing.T) {
+	output := influxdb.InfluxDB{
+		URLs:      []string{"http://localhost:8086"},
+		WriteMode: "some",
+	}
+
+	err := output.Connect()
+	require.Error(t, err)
+}
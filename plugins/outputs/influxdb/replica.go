@@ -0,0 +1,173 @@
+package influxdb
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+const (
+	// replicaBatchSize is the maximum number of metrics of each write.
+	replicaBatchSize = 1000
+
+	defaultRetryInterval    = time.Second
+	defaultMaxRetryInterval = time.Minute
+)
+
+// replica writes metrics to a single client in the background, used when
+// writing to all URLs.  Metrics are buffered while the client is failing and
+// the write is retried with an exponential backoff, so that a failing server
+// doesn't block writes to the others.
+type replica struct {
+	client      Client
+	bufferLimit int
+	handleError func(context.Context, Client, error)
+	retry       time.Duration
+	maxRetry    time.Duration
+	notify      chan struct{}
+	done        chan struct{}
+	wg          sync.WaitGroup
+	mu          sync.Mutex
+	buf         []telegraf.Metric
+	written     selfstat.Stat
+	dropped     selfstat.Stat
+	errors      selfstat.Stat
+	bufferSize  selfstat.Stat
+	limit       selfstat.Stat
+}
+
+func newReplica(client Client, bufferLimit int, handleError func(context.Context, Client, error)) *replica {
+	tags := map[string]string{
+		"output": "influxdb",
+		"url":    redactURL(client.URL()),
+	}
+	r := &replica{
+		client:      client,
+		bufferLimit: bufferLimit,
+		handleError: handleError,
+		retry:       defaultRetryInterval,
+		maxRetry:    defaultMaxRetryInterval,
+		notify:      make(chan struct{}, 1),
+		done:        make(chan struct{}),
+		written:     selfstat.Register("write", "metrics_written", tags),
+		dropped:     selfstat.Register("write", "metrics_dropped", tags),
+		errors:      selfstat.Register("write", "errors", tags),
+		bufferSize:  selfstat.Register("write", "buffer_size", tags),
+		limit:       selfstat.Register("write", "buffer_limit", tags),
+	}
+	r.limit.Set(int64(bufferLimit))
+	return r
+}
+
+func (r *replica) start() {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.run()
+	}()
+}
+
+// stop stops the background writes, after a last attempt to write the
+// buffered metrics.
+func (r *replica) stop() {
+	close(r.done)
+	r.wg.Wait()
+}
+
+// add buffers the metrics, dropping the oldest metrics if the buffer is full.
+func (r *replica) add(metrics []telegraf.Metric) {
+	r.mu.Lock()
+	r.buf = append(r.buf, metrics...)
+	r.trim()
+	r.mu.Unlock()
+
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
+
+// trim drops the oldest metrics exceeding the buffer limit, the lock must be
+// held.
+func (r *replica) trim() {
+	if over := len(r.buf) - r.bufferLimit; over > 0 {
+		r.buf = r.buf[over:]
+		r.dropped.Incr(int64(over))
+	}
+	r.bufferSize.Set(int64(len(r.buf)))
+}
+
+func (r *replica) run() {
+	for {
+		select {
+		case <-r.done:
+			r.flush()
+			return
+		case <-r.notify:
+		}
+
+		backoff := r.retry
+		for !r.flush() {
+			select {
+			case <-r.done:
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > r.maxRetry {
+				backoff = r.maxRetry
+			}
+		}
+	}
+}
+
+// flush writes the buffered metrics in batches, returning false if a write
+// fails.  The metrics of a failed write are returned to the buffer.
+func (r *replica) flush() bool {
+	ctx := context.Background()
+	for {
+		r.mu.Lock()
+		n := len(r.buf)
+		if n > replicaBatchSize {
+			n = replicaBatchSize
+		}
+		batch := r.buf[:n:n]
+		r.buf = r.buf[n:]
+		r.mu.Unlock()
+
+		if len(batch) == 0 {
+			return true
+		}
+
+		err := r.client.Write(ctx, batch)
+		if err != nil {
+			r.errors.Incr(1)
+			r.handleError(ctx, r.client, err)
+
+			r.mu.Lock()
+			r.buf = append(batch, r.buf...)
+			r.trim()
+			r.mu.Unlock()
+			return false
+		}
+
+		r.mu.Lock()
+		r.bufferSize.Set(int64(len(r.buf)))
+		r.mu.Unlock()
+		r.written.Incr(int64(n))
+	}
+}
+
+// redactURL removes the credentials from the url.
+func redactURL(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return rawurl
+	}
+	u.User = nil
+	return u.String()
+}
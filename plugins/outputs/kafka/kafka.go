@@ -29,6 +29,18 @@ type (
 		TopicSuffix TopicSuffix `toml:"topic_suffix"`
 		// Routing Key Tag
 		RoutingTag string `toml:"routing_tag"`
+		// Routing Key Template
+		KeyTemplate string `toml:"key_template"`
+		// Tags sent as record headers
+		HeaderTags []string `toml:"header_tags"`
+		// Partitioner choosing the partition of the messages
+		Partitioner string `toml:"partitioner"`
+		// Partition Tag used by the manual partitioner
+		PartitionTag string `toml:"partition_tag"`
+		// Kafka version of the brokers
+		Version string `toml:"version"`
+		// Wait for all replicas and send requests one at a time
+		OrderedWrites bool `toml:"ordered_writes"`
 		// Compression Codec Tag
 		CompressionCodec int
 		// RequiredAcks Tag
@@ -51,8 +63,9 @@ type (
 		// SASL Password
 		SASLPassword string `toml:"sasl_password"`
 
-		tlsConfig tls.Config
-		producer  sarama.SyncProducer
+		tlsConfig   tls.Config
+		producer    sarama.SyncProducer
+		keyTemplate *keyTemplate
 
 		serializer serializers.Serializer
 	}
@@ -100,6 +113,24 @@ var sampleConfig = `
   ##  ie, if this tag exists, its value will be used as the routing key
   routing_tag = "host"
 
+  ## Go template of the routing key, using the metric name and tags.  Takes
+  ## precedence over routing_tag.
+  # key_template = '{{.Name}}/{{.Tag "host"}}'
+
+  ## Tags to send as record headers, requires a version of at least 0.11.0.0.
+  # header_tags = ["host"]
+
+  ## Kafka version of the brokers, used to enable newer protocol features.
+  # version = "0.11.0.0"
+
+  ## Partitioner choosing the partition of the messages:
+  ##   hash        - hash of the routing key, random if there is no key
+  ##   series      - hash of the metric name and tags
+  ##   round_robin - partitions in turn
+  ##   manual      - partition number from the value of partition_tag
+  # partitioner = "hash"
+  # partition_tag = "partition"
+
   ## CompressionCodec represents the various compression codecs recognized by
   ## Kafka in messages.
   ##  0 : No compression
@@ -127,6 +158,11 @@ var sampleConfig = `
   ## until the next flush.
   # max_retry = 3
 
+  ## Wait for all in-sync replicas and send a single request at a time per
+  ## broker, so that retried messages are not reordered.  Retried messages
+  ## may still be written twice.
+  # ordered_writes = false
+
   ## Optional TLS Config
   # tls_ca = "/etc/telegraf/ca.pem"
   # tls_cert = "/etc/telegraf/cert.pem"
@@ -184,12 +220,10 @@ func (k *Kafka) Connect() error {
 	if err != nil {
 		return err
 	}
-	config := sarama.NewConfig()
-
-	config.Producer.RequiredAcks = sarama.RequiredAcks(k.RequiredAcks)
-	config.Producer.Compression = sarama.CompressionCodec(k.CompressionCodec)
-	config.Producer.Retry.Max = k.MaxRetry
-	config.Producer.Return.Successes = true
+	config, err := k.producerConfig()
+	if err != nil {
+		return err
+	}
 
 	// Legacy support ssl config
 	if k.Certificate != "" {
@@ -222,6 +256,50 @@ func (k *Kafka) Connect() error {
 	return nil
 }
 
+// producerConfig returns the sarama configuration of the producer, except
+// for the connection settings.
+func (k *Kafka) producerConfig() (*sarama.Config, error) {
+	config := sarama.NewConfig()
+
+	config.Producer.RequiredAcks = sarama.RequiredAcks(k.RequiredAcks)
+	config.Producer.Compression = sarama.CompressionCodec(k.CompressionCodec)
+	config.Producer.Retry.Max = k.MaxRetry
+	config.Producer.Return.Successes = true
+
+	if k.Version != "" {
+		version, err := sarama.ParseKafkaVersion(k.Version)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q: %s", k.Version, err)
+		}
+		config.Version = version
+	}
+	if len(k.HeaderTags) > 0 && !config.Version.IsAtLeast(sarama.V0_11_0_0) {
+		return nil, fmt.Errorf("header_tags requires a version of at least 0.11.0.0")
+	}
+
+	if k.OrderedWrites {
+		config.Producer.RequiredAcks = sarama.WaitForAll
+		config.Net.MaxOpenRequests = 1
+		if config.Producer.Retry.Max < 1 {
+			config.Producer.Retry.Max = 1
+		}
+	}
+
+	partitioner, err := newPartitioner(k.Partitioner, k.PartitionTag)
+	if err != nil {
+		return nil, err
+	}
+	config.Producer.Partitioner = partitioner
+
+	if k.KeyTemplate != "" {
+		k.keyTemplate, err = newKeyTemplate(k.KeyTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key_template %q: %s", k.KeyTemplate, err)
+		}
+	}
+	return config, nil
+}
+
 func (k *Kafka) Close() error {
 	return k.producer.Close()
 }
@@ -245,14 +323,9 @@ func (k *Kafka) Write(metrics []telegraf.Metric) error {
 			return err
 		}
 
-		topicName := k.GetTopicName(metric)
-
-		m := &sarama.ProducerMessage{
-			Topic: topicName,
-			Value: sarama.ByteEncoder(buf),
-		}
-		if h, ok := metric.Tags()[k.RoutingTag]; ok {
-			m.Key = sarama.StringEncoder(h)
+		m, err := k.message(metric, buf)
+		if err != nil {
+			return err
 		}
 
 		_, _, err = k.producer.SendMessage(m)
@@ -264,6 +337,35 @@ func (k *Kafka) Write(metrics []telegraf.Metric) error {
 	return nil
 }
 
+// message returns the producer message of the serialized metric.
+func (k *Kafka) message(metric telegraf.Metric, buf []byte) (*sarama.ProducerMessage, error) {
+	m := &sarama.ProducerMessage{
+		Topic:    k.GetTopicName(metric),
+		Value:    sarama.ByteEncoder(buf),
+		Metadata: metric,
+	}
+
+	if k.keyTemplate != nil {
+		key, err := k.keyTemplate.render(metric)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render routing key: %s", err)
+		}
+		m.Key = sarama.StringEncoder(key)
+	} else if h, ok := metric.Tags()[k.RoutingTag]; ok {
+		m.Key = sarama.StringEncoder(h)
+	}
+
+	for _, key := range k.HeaderTags {
+		if value, ok := metric.GetTag(key); ok {
+			m.Headers = append(m.Headers, sarama.RecordHeader{
+				Key:   []byte(key),
+				Value: []byte(value),
+			})
+		}
+	}
+	return m, nil
+}
+
 func init() {
 	outputs.Add("kafka", func() telegraf.Output {
 		return &Kafka{
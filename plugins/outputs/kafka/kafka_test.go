@@ -1,8 +1,12 @@
 package kafka
 
 import (
+	"bytes"
+	"fmt"
 	"testing"
 
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/mocks"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
@@ -96,3 +100,101 @@ func TestValidateTopicSuffixMethod(t *testing.T) {
 		require.NoError(t, err, "Topic suffix method used should be valid.")
 	}
 }
+
+func newTestKafka(t *testing.T, k *Kafka) *mocks.SyncProducer {
+	s, _ := serializers.NewInfluxSerializer()
+	k.serializer = s
+	_, err := k.producerConfig()
+	require.NoError(t, err)
+
+	producer := mocks.NewSyncProducer(t, nil)
+	k.producer = producer
+	return producer
+}
+
+func TestWriteMock(t *testing.T) {
+	k := &Kafka{Topic: "Test"}
+	producer := newTestKafka(t, k)
+	defer k.Close()
+
+	metrics := testutil.MockMetrics()
+	for range metrics {
+		producer.ExpectSendMessageWithCheckerFunctionAndSucceed(func(val []byte) error {
+			if !bytes.HasPrefix(val, []byte("test1,")) {
+				return fmt.Errorf("unexpected message %q", val)
+			}
+			return nil
+		})
+	}
+	require.NoError(t, k.Write(metrics))
+
+	producer.ExpectSendMessageAndFail(sarama.ErrOutOfBrokers)
+	require.Error(t, k.Write(metrics))
+}
+
+func TestMessageKey(t *testing.T) {
+	metric := testutil.TestMetric(1)
+
+	k := &Kafka{Topic: "Test", RoutingTag: "tag1"}
+	newTestKafka(t, k)
+	m, err := k.message(metric, nil)
+	require.NoError(t, err)
+	require.Equal(t, sarama.StringEncoder("value1"), m.Key)
+	require.Equal(t, metric, m.Metadata)
+
+	k = &Kafka{Topic: "Test", RoutingTag: "tag1", KeyTemplate: `{{.Name}}/{{.Tag "tag1"}}/{{.Tag "missing"}}`}
+	newTestKafka(t, k)
+	m, err = k.message(metric, nil)
+	require.NoError(t, err)
+	require.Equal(t, sarama.StringEncoder("test1/value1/"), m.Key)
+
+	k = &Kafka{Topic: "Test"}
+	newTestKafka(t, k)
+	m, err = k.message(metric, nil)
+	require.NoError(t, err)
+	require.Nil(t, m.Key)
+}
+
+func TestMessageHeaders(t *testing.T) {
+	k := &Kafka{
+		Topic:      "Test",
+		Version:    "0.11.0.0",
+		HeaderTags: []string{"tag1", "missing"},
+	}
+	newTestKafka(t, k)
+
+	m, err := k.message(testutil.TestMetric(1), nil)
+	require.NoError(t, err)
+	require.Equal(t, []sarama.RecordHeader{
+		{Key: []byte("tag1"), Value: []byte("value1")},
+	}, m.Headers)
+}
+
+func TestProducerConfig(t *testing.T) {
+	k := &Kafka{HeaderTags: []string{"tag1"}}
+	_, err := k.producerConfig()
+	require.Error(t, err)
+
+	k = &Kafka{Version: "invalid"}
+	_, err = k.producerConfig()
+	require.Error(t, err)
+
+	k = &Kafka{KeyTemplate: "{{.Name"}
+	_, err = k.producerConfig()
+	require.Error(t, err)
+
+	k = &Kafka{Partitioner: "manual"}
+	_, err = k.producerConfig()
+	require.Error(t, err)
+
+	k = &Kafka{Partitioner: "unknown"}
+	_, err = k.producerConfig()
+	require.Error(t, err)
+
+	k = &Kafka{RequiredAcks: 1, OrderedWrites: true}
+	config, err := k.producerConfig()
+	require.NoError(t, err)
+	require.Equal(t, sarama.WaitForAll, config.Producer.RequiredAcks)
+	require.Equal(t, 1, config.Net.MaxOpenRequests)
+	require.Equal(t, 1, config.Producer.Retry.Max)
+}
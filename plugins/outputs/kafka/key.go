@@ -0,0 +1,31 @@
+package kafka
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/influxdata/telegraf"
+	tmpldata "github.com/influxdata/telegraf/plugins/serializers/template"
+)
+
+// keyTemplate is the message key rendered for each metric using a Go
+// template, for example `{{.Name}}/{{.Tag "host"}}`.
+type keyTemplate struct {
+	tmpl *template.Template
+}
+
+func newKeyTemplate(text string) (*keyTemplate, error) {
+	tmpl, err := template.New("key").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &keyTemplate{tmpl: tmpl}, nil
+}
+
+func (k *keyTemplate) render(metric telegraf.Metric) (string, error) {
+	var buf bytes.Buffer
+	if err := k.tmpl.Execute(&buf, tmpldata.NewMetric(metric)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
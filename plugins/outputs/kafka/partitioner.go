@@ -0,0 +1,68 @@
+package kafka
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/Shopify/sarama"
+	"github.com/influxdata/telegraf"
+)
+
+// newPartitioner returns the constructor of the partitioner choosing the
+// partition of the messages.
+func newPartitioner(method string, partitionTag string) (sarama.PartitionerConstructor, error) {
+	switch method {
+	case "", "hash":
+		return sarama.NewHashPartitioner, nil
+	case "round_robin":
+		return sarama.NewRoundRobinPartitioner, nil
+	case "series":
+		return func(topic string) sarama.Partitioner {
+			return &metricPartitioner{}
+		}, nil
+	case "manual":
+		if partitionTag == "" {
+			return nil, fmt.Errorf("partition_tag is required by the manual partitioner")
+		}
+		return func(topic string) sarama.Partitioner {
+			return &metricPartitioner{tag: partitionTag}
+		}, nil
+	default:
+		return nil, fmt.Errorf("Unknown partitioner provided: %s", method)
+	}
+}
+
+// metricPartitioner chooses the partition from the metric of the message,
+// stored in its metadata.  Without a tag the partition is chosen by the hash
+// of the series, otherwise it is the value of the tag.
+type metricPartitioner struct {
+	tag string
+}
+
+func (p *metricPartitioner) Partition(message *sarama.ProducerMessage, numPartitions int32) (int32, error) {
+	metric, ok := message.Metadata.(telegraf.Metric)
+	if !ok {
+		return 0, nil
+	}
+
+	if p.tag == "" {
+		return int32(metric.HashID() % uint64(numPartitions)), nil
+	}
+
+	value, ok := metric.GetTag(p.tag)
+	if !ok {
+		return 0, nil
+	}
+	partition, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || partition < 0 || int32(partition) >= numPartitions {
+		log.Printf("W! [outputs.kafka] invalid partition %q of topic %q, using partition 0",
+			value, message.Topic)
+		return 0, nil
+	}
+	return int32(partition), nil
+}
+
+func (p *metricPartitioner) RequiresConsistency() bool {
+	return true
+}
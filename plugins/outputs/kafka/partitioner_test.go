@@ -0,0 +1,52 @@
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func partition(t *testing.T, method string, m telegraf.Metric) int32 {
+	constructor, err := newPartitioner(method, "partition")
+	require.NoError(t, err)
+
+	partition, err := constructor("Test").Partition(&sarama.ProducerMessage{
+		Topic:    "Test",
+		Metadata: m,
+	}, 4)
+	require.NoError(t, err)
+	return partition
+}
+
+func TestSeriesPartitioner(t *testing.T) {
+	m1 := testutil.MustMetric("cpu", map[string]string{"host": "a"},
+		map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	m2 := testutil.MustMetric("cpu", map[string]string{"host": "a"},
+		map[string]interface{}{"value": 2}, time.Unix(10, 0))
+
+	p := partition(t, "series", m1)
+	require.True(t, p >= 0 && p < 4)
+	require.Equal(t, p, partition(t, "series", m2))
+}
+
+func TestManualPartitioner(t *testing.T) {
+	tests := []struct {
+		tags     map[string]string
+		expected int32
+	}{
+		{map[string]string{"partition": "3"}, 3},
+		{map[string]string{"partition": "4"}, 0},
+		{map[string]string{"partition": "-1"}, 0},
+		{map[string]string{"partition": "a"}, 0},
+		{map[string]string{}, 0},
+	}
+	for _, tt := range tests {
+		m := testutil.MustMetric("cpu", tt.tags,
+			map[string]interface{}{"value": 1}, time.Unix(0, 0))
+		require.Equal(t, tt.expected, partition(t, "manual", m))
+	}
+}
@@ -0,0 +1,44 @@
+package loki
+
+import (
+	"github.com/golang/protobuf/proto"
+)
+
+// The messages of the Loki push API, see
+// https://github.com/grafana/loki/blob/master/pkg/logproto/logproto.proto
+
+type pushRequest struct {
+	Streams []*stream `protobuf:"bytes,1,rep,name=streams" json:"streams,omitempty"`
+}
+
+func (m *pushRequest) Reset()         { *m = pushRequest{} }
+func (m *pushRequest) String() string { return proto.CompactTextString(m) }
+func (*pushRequest) ProtoMessage()    {}
+
+type stream struct {
+	Labels  string   `protobuf:"bytes,1,opt,name=labels,proto3" json:"labels,omitempty"`
+	Entries []*entry `protobuf:"bytes,2,rep,name=entries" json:"entries,omitempty"`
+}
+
+func (m *stream) Reset()         { *m = stream{} }
+func (m *stream) String() string { return proto.CompactTextString(m) }
+func (*stream) ProtoMessage()    {}
+
+type entry struct {
+	Timestamp *timestamp `protobuf:"bytes,1,opt,name=timestamp" json:"timestamp,omitempty"`
+	Line      string     `protobuf:"bytes,2,opt,name=line,proto3" json:"line,omitempty"`
+}
+
+func (m *entry) Reset()         { *m = entry{} }
+func (m *entry) String() string { return proto.CompactTextString(m) }
+func (*entry) ProtoMessage()    {}
+
+// timestamp is a google.protobuf.Timestamp.
+type timestamp struct {
+	Seconds int64 `protobuf:"varint,1,opt,name=seconds,proto3" json:"seconds,omitempty"`
+	Nanos   int32 `protobuf:"varint,2,opt,name=nanos,proto3" json:"nanos,omitempty"`
+}
+
+func (m *timestamp) Reset()         { *m = timestamp{} }
+func (m *timestamp) String() string { return proto.CompactTextString(m) }
+func (*timestamp) ProtoMessage()    {}
@@ -0,0 +1,210 @@
+package loki
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/httppush"
+	"github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+var sampleConfig = `
+  ## URL of the push endpoint.
+  url = "http://127.0.0.1:3100/loki/api/v1/push"
+
+  ## Timeout for HTTP requests.
+  # timeout = "5s"
+
+  ## HTTP Basic Auth credentials
+  # username = "username"
+  # password = "pa$$word"
+
+  ## Additional HTTP headers
+  # [outputs.loki.headers]
+  #   X-Scope-OrgID = "tenant"
+
+  ## Field used as the log line, metrics without the field are sent with all
+  ## their fields formatted as logfmt.
+  # line_field = "message"
+
+  ## Format of the requests, "json" or "protobuf".  Protobuf requests are
+  ## always snappy compressed.
+  # format = "json"
+
+  ## HTTP Content-Encoding of json requests, "identity" or "gzip".
+  # content_encoding = "identity"
+
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
+  # insecure_skip_verify = false
+`
+
+const (
+	defaultTimeout   = 5 * time.Second
+	defaultLineField = "message"
+)
+
+type Loki struct {
+	URL             string            `toml:"url"`
+	Timeout         internal.Duration `toml:"timeout"`
+	Username        string            `toml:"username"`
+	Password        string            `toml:"password"`
+	Headers         map[string]string `toml:"headers"`
+	LineField       string            `toml:"line_field"`
+	Format          string            `toml:"format"`
+	ContentEncoding string            `toml:"content_encoding"`
+	tls.ClientConfig
+
+	client *http.Client
+}
+
+func (l *Loki) Description() string {
+	return "Send metrics as log lines to Loki"
+}
+
+func (l *Loki) SampleConfig() string {
+	return sampleConfig
+}
+
+func (l *Loki) Connect() error {
+	if l.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+	if l.Timeout.Duration == 0 {
+		l.Timeout.Duration = defaultTimeout
+	}
+	if l.LineField == "" {
+		l.LineField = defaultLineField
+	}
+
+	switch l.Format {
+	case "", "json", "protobuf":
+	default:
+		return fmt.Errorf("invalid format %q", l.Format)
+	}
+	switch l.ContentEncoding {
+	case "", "identity", "gzip":
+	default:
+		return fmt.Errorf("invalid content_encoding %q", l.ContentEncoding)
+	}
+
+	tlsCfg, err := l.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	l.client = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsCfg,
+			Proxy:           http.ProxyFromEnvironment,
+		},
+		Timeout: l.Timeout.Duration,
+	}
+	return nil
+}
+
+func (l *Loki) Close() error {
+	return nil
+}
+
+// Write sends the metrics in a single push request.  Loki rejects entries
+// older than the last entry of their stream, such a batch is logged and
+// dropped instead of being retried.
+func (l *Loki) Write(metrics []telegraf.Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	s := newStreams(l.LineField)
+	for _, metric := range metrics {
+		s.add(metric)
+	}
+
+	body, contentType, contentEncoding, err := l.encode(s)
+	if err != nil {
+		return err
+	}
+
+	err = l.post(body, contentType, contentEncoding)
+	if httppush.IsRecoverable(err) {
+		return err
+	}
+	if err != nil {
+		log.Printf("E! [outputs.loki] dropping %d entries: %v", len(metrics), err)
+	}
+	return nil
+}
+
+// encode returns the body of the push request of the streams, with its
+// content type and encoding.
+func (l *Loki) encode(s *streams) ([]byte, string, string, error) {
+	if l.Format == "protobuf" {
+		buf, err := proto.Marshal(s.pushRequest())
+		if err != nil {
+			return nil, "", "", err
+		}
+		return snappy.Encode(nil, buf), "application/x-protobuf", "", nil
+	}
+
+	buf, err := json.Marshal(s.jsonPushRequest())
+	if err != nil {
+		return nil, "", "", err
+	}
+	if l.ContentEncoding != "gzip" {
+		return buf, "application/json", "", nil
+	}
+
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	if _, err := gz.Write(buf); err != nil {
+		return nil, "", "", err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, "", "", err
+	}
+	return body.Bytes(), "application/json", "gzip", nil
+}
+
+func (l *Loki) post(body []byte, contentType, contentEncoding string) error {
+	req, err := http.NewRequest(http.MethodPost, l.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	if contentEncoding != "" {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
+	req.Header.Set("User-Agent", "telegraf")
+	for k, v := range l.Headers {
+		req.Header.Set(k, v)
+	}
+	if l.Username != "" || l.Password != "" {
+		req.SetBasicAuth(l.Username, l.Password)
+	}
+
+	return httppush.Do(l.client, req)
+}
+
+func init() {
+	outputs.Add("loki", func() telegraf.Output {
+		return &Loki{
+			Timeout:   internal.Duration{Duration: defaultTimeout},
+			LineField: defaultLineField,
+		}
+	})
+}
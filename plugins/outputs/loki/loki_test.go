@@ -0,0 +1,156 @@
+package loki
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func testMetrics() []telegraf.Metric {
+	return []telegraf.Metric{
+		testutil.MustMetric("syslog",
+			map[string]string{"host": "a", "app-name": "sshd"},
+			map[string]interface{}{"message": "second", "severity_code": 6},
+			time.Unix(2, 0)),
+		testutil.MustMetric("syslog",
+			map[string]string{"host": "b", "app-name": "sshd"},
+			map[string]interface{}{"message": "other"},
+			time.Unix(1, 0)),
+		testutil.MustMetric("syslog",
+			map[string]string{"host": "a", "app-name": "sshd"},
+			map[string]interface{}{"message": "first"},
+			time.Unix(1, 500)),
+		testutil.MustMetric("cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage": 1.5, "state": "ok"},
+			time.Unix(3, 0)),
+	}
+}
+
+func newTestLoki(url string) *Loki {
+	return &Loki{
+		URL:       url,
+		LineField: defaultLineField,
+		Headers:   map[string]string{"X-Scope-OrgID": "tenant"},
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+	}{
+		{"identity", ""},
+		{"gzip", "gzip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req jsonPushRequest
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				require.Equal(t, "tenant", r.Header.Get("X-Scope-OrgID"))
+
+				body := r.Body
+				if tt.encoding == "gzip" {
+					require.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+					gz, err := gzip.NewReader(r.Body)
+					require.NoError(t, err)
+					body = gz
+				}
+				require.NoError(t, json.NewDecoder(body).Decode(&req))
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer ts.Close()
+
+			l := newTestLoki(ts.URL)
+			l.ContentEncoding = tt.encoding
+			require.NoError(t, l.Connect())
+			require.NoError(t, l.Write(testMetrics()))
+
+			require.Equal(t, jsonPushRequest{Streams: []jsonStream{
+				{
+					Stream: map[string]string{"measurement": "syslog", "host": "a", "app_name": "sshd"},
+					Values: [][2]string{{"1000000500", "first"}, {"2000000000", "second"}},
+				},
+				{
+					Stream: map[string]string{"measurement": "syslog", "host": "b", "app_name": "sshd"},
+					Values: [][2]string{{"1000000000", "other"}},
+				},
+				{
+					Stream: map[string]string{"measurement": "cpu", "host": "a"},
+					Values: [][2]string{{"3000000000", `state="ok" usage=1.5`}},
+				},
+			}}, req)
+		})
+	}
+}
+
+func TestWriteProtobuf(t *testing.T) {
+	var req pushRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+
+		compressed, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		buf, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+		require.NoError(t, proto.Unmarshal(buf, &req))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	l := newTestLoki(ts.URL)
+	l.Format = "protobuf"
+	require.NoError(t, l.Connect())
+	require.NoError(t, l.Write(testMetrics()[:3]))
+
+	require.Len(t, req.Streams, 2)
+	require.Equal(t, `{app_name="sshd", host="a", measurement="syslog"}`, req.Streams[0].Labels)
+	require.Equal(t, []*entry{
+		{Timestamp: &timestamp{Seconds: 1, Nanos: 500}, Line: "first"},
+		{Timestamp: &timestamp{Seconds: 2}, Line: "second"},
+	}, req.Streams[0].Entries)
+	require.Equal(t, `{app_name="sshd", host="b", measurement="syslog"}`, req.Streams[1].Labels)
+}
+
+func TestWriteErrors(t *testing.T) {
+	status := http.StatusBadRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte("entry out of order"))
+	}))
+	defer ts.Close()
+
+	l := newTestLoki(ts.URL)
+	require.NoError(t, l.Connect())
+
+	// invalid requests are dropped
+	require.NoError(t, l.Write(testMetrics()))
+
+	status = http.StatusTooManyRequests
+	require.Error(t, l.Write(testMetrics()))
+
+	status = http.StatusServiceUnavailable
+	require.Error(t, l.Write(testMetrics()))
+}
+
+func TestConnectErrors(t *testing.T) {
+	l := &Loki{}
+	require.Error(t, l.Connect())
+
+	l = &Loki{URL: "http://127.0.0.1", Format: "xml"}
+	require.Error(t, l.Connect())
+
+	l = &Loki{URL: "http://127.0.0.1", ContentEncoding: "br"}
+	require.Error(t, l.Connect())
+}
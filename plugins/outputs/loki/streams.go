@@ -0,0 +1,166 @@
+package loki
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
+)
+
+// measurementLabel is the label of the metric name.
+const measurementLabel = "measurement"
+
+// logEntry is a log line of a stream.
+type logEntry struct {
+	time time.Time
+	line string
+}
+
+// logStream is a stream of log lines with the same label set.
+type logStream struct {
+	labels  map[string]string
+	entries []logEntry
+}
+
+// streams groups the log lines of metrics into streams by label set, in
+// the order the streams are first seen.
+type streams struct {
+	lineField string
+
+	byKey map[string]*logStream
+	keys  []string
+}
+
+func newStreams(lineField string) *streams {
+	return &streams{
+		lineField: lineField,
+		byKey:     make(map[string]*logStream),
+	}
+}
+
+// add adds the log line of the metric to the stream of its name and tags.
+func (s *streams) add(metric telegraf.Metric) {
+	labels := prometheus.Labels(metric, false)
+	labels[measurementLabel] = metric.Name()
+
+	key := labelString(labels)
+	ls, ok := s.byKey[key]
+	if !ok {
+		ls = &logStream{labels: labels}
+		s.byKey[key] = ls
+		s.keys = append(s.keys, key)
+	}
+	ls.entries = append(ls.entries, logEntry{
+		time: metric.Time(),
+		line: s.line(metric),
+	})
+}
+
+// line returns the value of the line field, or all fields formatted as
+// logfmt if the metric does not have the field.
+func (s *streams) line(metric telegraf.Metric) string {
+	if v, ok := metric.GetField(s.lineField); ok {
+		return formatValue(v)
+	}
+
+	fields := metric.FieldList()
+	pairs := make([]string, 0, len(fields))
+	for _, field := range fields {
+		value := formatValue(field.Value)
+		if _, ok := field.Value.(string); ok {
+			value = strconv.Quote(value)
+		}
+		pairs = append(pairs, field.Key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, " ")
+}
+
+// sorted returns the streams with their entries sorted by time, as
+// required by Loki.
+func (s *streams) sorted() []*logStream {
+	result := make([]*logStream, 0, len(s.keys))
+	for _, key := range s.keys {
+		ls := s.byKey[key]
+		sort.SliceStable(ls.entries, func(i, j int) bool {
+			return ls.entries[i].time.Before(ls.entries[j].time)
+		})
+		result = append(result, ls)
+	}
+	return result
+}
+
+// pushRequest returns the protobuf push request of the streams.
+func (s *streams) pushRequest() *pushRequest {
+	req := &pushRequest{}
+	for _, ls := range s.sorted() {
+		st := &stream{Labels: labelString(ls.labels)}
+		for _, e := range ls.entries {
+			st.Entries = append(st.Entries, &entry{
+				Timestamp: &timestamp{
+					Seconds: e.time.Unix(),
+					Nanos:   int32(e.time.Nanosecond()),
+				},
+				Line: e.line,
+			})
+		}
+		req.Streams = append(req.Streams, st)
+	}
+	return req
+}
+
+type jsonStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type jsonPushRequest struct {
+	Streams []jsonStream `json:"streams"`
+}
+
+// jsonPushRequest returns the JSON push request of the streams.
+func (s *streams) jsonPushRequest() *jsonPushRequest {
+	req := &jsonPushRequest{Streams: []jsonStream{}}
+	for _, ls := range s.sorted() {
+		st := jsonStream{Stream: ls.labels}
+		for _, e := range ls.entries {
+			st.Values = append(st.Values, [2]string{
+				strconv.FormatInt(e.time.UnixNano(), 10),
+				e.line,
+			})
+		}
+		req.Streams = append(req.Streams, st)
+	}
+	return req
+}
+
+// labelString returns the label set in the Prometheus selector format, for
+// example `{host="a", measurement="syslog"}`.
+func labelString(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+strconv.Quote(labels[k]))
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
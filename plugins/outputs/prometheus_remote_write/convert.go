@@ -0,0 +1,213 @@
+package prometheus_remote_write
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/influxdata/telegraf"
+	parser "github.com/influxdata/telegraf/plugins/parsers/prometheus"
+	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
+)
+
+// converter groups the samples of metrics into time series by label set.
+type converter struct {
+	stringAsLabel bool
+
+	series map[string]*timeSeries
+	keys   []string
+	count  int
+
+	// ids are the identifiers of the samples of each request.
+	ids [][]string
+}
+
+func newConverter(stringAsLabel bool) *converter {
+	return &converter{
+		stringAsLabel: stringAsLabel,
+		series:        make(map[string]*timeSeries),
+	}
+}
+
+// add converts the metric to samples.  Summaries and histograms are split
+// into the series of their quantiles or buckets, sum and count, as in the
+// Prometheus exposition format.
+func (c *converter) add(metric telegraf.Metric) {
+	labels := c.labels(metric)
+	timestamp := metric.Time().UnixNano() / 1000000
+	name := metric.Name()
+	metricType := parser.MetricType(metric.Type())
+
+	switch metricType {
+	case dto.MetricType_SUMMARY, dto.MetricType_HISTOGRAM:
+		labelName, suffix := "quantile", ""
+		if metricType == dto.MetricType_HISTOGRAM {
+			labelName, suffix = "le", "_bucket"
+		}
+
+		var count float64
+		var hasInf bool
+		for _, field := range metric.FieldList() {
+			value, ok := prometheus.FloatValue(field.Value)
+			if !ok {
+				continue
+			}
+			switch field.Key {
+			case "count":
+				count = value
+				c.addSample(name+"_count", labels, value, timestamp)
+			case "sum":
+				c.addSample(name+"_sum", labels, value, timestamp)
+			default:
+				bound, err := strconv.ParseFloat(field.Key, 64)
+				if err != nil {
+					continue
+				}
+				hasInf = hasInf || math.IsInf(bound, 1)
+				c.addSample(name+suffix, withLabel(labels, labelName, formatFloat(bound)), value, timestamp)
+			}
+		}
+
+		// The +Inf bucket holds all observations.
+		if metricType == dto.MetricType_HISTOGRAM && !hasInf {
+			c.addSample(name+suffix, withLabel(labels, labelName, "+Inf"), count, timestamp)
+		}
+	default:
+		for _, field := range metric.FieldList() {
+			value, ok := prometheus.FloatValue(field.Value)
+			if !ok {
+				continue
+			}
+
+			// The value field of each type is named after the metric, as
+			// created by the prometheus parser.
+			fieldName := name
+			if field.Key != parser.ValueFieldName(metricType) && field.Key != "value" {
+				fieldName = name + "_" + field.Key
+			}
+			c.addSample(fieldName, labels, value, timestamp)
+		}
+	}
+}
+
+func (c *converter) addSample(name string, labels map[string]string, value float64, timestamp int64) {
+	labels = withLabel(labels, "__name__", prometheus.SanitizeName(name))
+	key := prometheus.SeriesKey(labels)
+
+	ts, ok := c.series[key]
+	if !ok {
+		ts = &timeSeries{Labels: sortedLabels(labels)}
+		c.series[key] = ts
+		c.keys = append(c.keys, key)
+	}
+	ts.Samples = append(ts.Samples, &sample{Value: value, Timestamp: timestamp})
+	c.count++
+}
+
+// skip removes the samples with an identifier in sent.
+func (c *converter) skip(sent map[string]bool) {
+	if len(sent) == 0 {
+		return
+	}
+	for _, key := range c.keys {
+		ts := c.series[key]
+		samples := ts.Samples[:0]
+		for _, s := range ts.Samples {
+			if !sent[sampleID(key, s.Timestamp)] {
+				samples = append(samples, s)
+			}
+		}
+		c.count -= len(ts.Samples) - len(samples)
+		ts.Samples = samples
+	}
+}
+
+// requests returns the time series as write requests of at most batchSize
+// samples, samples of each series are sorted by time.  The identifiers of
+// the samples of each request are kept in ids.
+func (c *converter) requests(batchSize int) []*writeRequest {
+	var requests []*writeRequest
+	c.ids = nil
+	req := &writeRequest{}
+	var ids []string
+	n := 0
+	for _, key := range c.keys {
+		ts := c.series[key]
+		sort.SliceStable(ts.Samples, func(i, j int) bool {
+			return ts.Samples[i].Timestamp < ts.Samples[j].Timestamp
+		})
+
+		samples := ts.Samples
+		for len(samples) > 0 {
+			if n == batchSize {
+				requests = append(requests, req)
+				c.ids = append(c.ids, ids)
+				req = &writeRequest{}
+				ids = nil
+				n = 0
+			}
+			size := batchSize - n
+			if size > len(samples) {
+				size = len(samples)
+			}
+			req.Timeseries = append(req.Timeseries, &timeSeries{
+				Labels:  ts.Labels,
+				Samples: samples[:size],
+			})
+			for _, s := range samples[:size] {
+				ids = append(ids, sampleID(key, s.Timestamp))
+			}
+			samples = samples[size:]
+			n += size
+		}
+	}
+	if n > 0 {
+		requests = append(requests, req)
+		c.ids = append(c.ids, ids)
+	}
+	return requests
+}
+
+// labels returns the labels of the metric, without empty values.
+func (c *converter) labels(metric telegraf.Metric) map[string]string {
+	labels := prometheus.Labels(metric, c.stringAsLabel)
+	for name, value := range labels {
+		if value == "" {
+			delete(labels, name)
+		}
+	}
+	return labels
+}
+
+// withLabel returns a copy of the labels with the label added.
+func withLabel(labels map[string]string, name, value string) map[string]string {
+	result := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		result[k] = v
+	}
+	result[name] = value
+	return result
+}
+
+// sortedLabels returns the labels sorted by name.
+func sortedLabels(labels map[string]string) []*label {
+	result := make([]*label, 0, len(labels))
+	for name, value := range labels {
+		result = append(result, &label{Name: name, Value: value})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// sampleID identifies a sample by its series and timestamp.
+func sampleID(key string, timestamp int64) string {
+	return key + "@" + strconv.FormatInt(timestamp, 10)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,210 @@
+package prometheus_remote_write
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/httppush"
+	"github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+var sampleConfig = `
+  ## URL of the remote write endpoint.
+  url = "http://127.0.0.1:9090/api/v1/write"
+
+  ## Timeout for HTTP requests.
+  # timeout = "5s"
+
+  ## HTTP Basic Auth credentials
+  # username = "username"
+  # password = "pa$$word"
+
+  ## Bearer token sent in the Authorization header.
+  # bearer_token = ""
+
+  ## Additional HTTP headers
+  # [outputs.prometheus_remote_write.headers]
+  #   X-Scope-OrgID = "tenant"
+
+  ## Maximum number of samples of each request.
+  # max_samples_per_send = 1000
+
+  ## Number of times to retry a request failing with a network error, a 5xx
+  ## or a 429 status code.  The interval between retries is doubled after
+  ## each retry.
+  # max_retries = 3
+  # retry_interval = "1s"
+
+  ## Send string fields as labels of the samples of the metric, by default
+  ## they are dropped.
+  # string_as_label = false
+
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
+  # insecure_skip_verify = false
+`
+
+const (
+	defaultTimeout           = 5 * time.Second
+	defaultMaxSamplesPerSend = 1000
+	defaultMaxRetries        = 3
+	defaultRetryInterval     = time.Second
+)
+
+type PrometheusRemoteWrite struct {
+	URL               string            `toml:"url"`
+	Timeout           internal.Duration `toml:"timeout"`
+	Username          string            `toml:"username"`
+	Password          string            `toml:"password"`
+	BearerToken       string            `toml:"bearer_token"`
+	Headers           map[string]string `toml:"headers"`
+	MaxSamplesPerSend int               `toml:"max_samples_per_send"`
+	MaxRetries        int               `toml:"max_retries"`
+	RetryInterval     internal.Duration `toml:"retry_interval"`
+	StringAsLabel     bool              `toml:"string_as_label"`
+	tls.ClientConfig
+
+	client *http.Client
+	// sent are the identifiers of the samples sent before a request of the
+	// previous write failed, they are skipped when the batch is written
+	// again.
+	sent map[string]bool
+}
+
+func (p *PrometheusRemoteWrite) Description() string {
+	return "Send metrics to a Prometheus remote write endpoint"
+}
+
+func (p *PrometheusRemoteWrite) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *PrometheusRemoteWrite) Connect() error {
+	if p.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+	if p.Timeout.Duration == 0 {
+		p.Timeout.Duration = defaultTimeout
+	}
+	if p.MaxSamplesPerSend <= 0 {
+		p.MaxSamplesPerSend = defaultMaxSamplesPerSend
+	}
+
+	tlsCfg, err := p.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	p.client = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsCfg,
+			Proxy:           http.ProxyFromEnvironment,
+		},
+		Timeout: p.Timeout.Duration,
+	}
+	return nil
+}
+
+func (p *PrometheusRemoteWrite) Close() error {
+	return nil
+}
+
+// Write sends the metrics in requests of at most max_samples_per_send
+// samples.  Requests rejected by the server as invalid are dropped, since
+// they would be rejected again.  If a request fails the samples of the
+// previous requests are remembered, so that writing the batch again resumes
+// with the failed request.
+func (p *PrometheusRemoteWrite) Write(metrics []telegraf.Metric) error {
+	c := newConverter(p.StringAsLabel)
+	for _, metric := range metrics {
+		c.add(metric)
+	}
+	c.skip(p.sent)
+
+	requests := c.requests(p.MaxSamplesPerSend)
+	for i, req := range requests {
+		buf, err := proto.Marshal(req)
+		if err != nil {
+			return err
+		}
+
+		err = p.send(snappy.Encode(nil, buf))
+		if httppush.IsRecoverable(err) {
+			if p.sent == nil {
+				p.sent = make(map[string]bool)
+			}
+			for _, ids := range c.ids[:i] {
+				for _, id := range ids {
+					p.sent[id] = true
+				}
+			}
+			return err
+		}
+		if err != nil {
+			log.Printf("E! [outputs.prometheus_remote_write] dropping %d series: %v", len(req.Timeseries), err)
+		}
+	}
+	p.sent = nil
+	return nil
+}
+
+// send posts the request body, retrying recoverable errors.
+func (p *PrometheusRemoteWrite) send(body []byte) error {
+	interval := p.RetryInterval.Duration
+	for i := 0; ; i++ {
+		err := p.post(body)
+		if !httppush.IsRecoverable(err) || i >= p.MaxRetries {
+			return err
+		}
+
+		log.Printf("W! [outputs.prometheus_remote_write] retrying in %s: %v", interval, err)
+		time.Sleep(interval)
+		interval *= 2
+	}
+}
+
+func (p *PrometheusRemoteWrite) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("User-Agent", "telegraf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	for k, v := range p.Headers {
+		req.Header.Set(k, v)
+	}
+	if p.Username != "" || p.Password != "" {
+		req.SetBasicAuth(p.Username, p.Password)
+	}
+	if p.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+p.BearerToken)
+	}
+
+	return httppush.Do(p.client, req)
+}
+
+func init() {
+	outputs.Add("prometheus_remote_write", func() telegraf.Output {
+		return &PrometheusRemoteWrite{
+			Timeout:           internal.Duration{Duration: defaultTimeout},
+			MaxSamplesPerSend: defaultMaxSamplesPerSend,
+			MaxRetries:        defaultMaxRetries,
+			RetryInterval:     internal.Duration{Duration: defaultRetryInterval},
+		}
+	})
+}
@@ -0,0 +1,298 @@
+package prometheus_remote_write
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// series is a time series in a comparable form.
+type series struct {
+	Labels  map[string]string
+	Samples []sample
+}
+
+func flatten(req *writeRequest) map[string]series {
+	result := make(map[string]series)
+	for _, ts := range req.Timeseries {
+		s := series{Labels: make(map[string]string)}
+		for _, l := range ts.Labels {
+			s.Labels[l.Name] = l.Value
+		}
+		for _, smp := range ts.Samples {
+			s.Samples = append(s.Samples, *smp)
+		}
+		result[prometheus.SeriesKey(s.Labels)] = s
+	}
+	return result
+}
+
+func decodeRequest(t *testing.T, r *http.Request) *writeRequest {
+	require.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+	require.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+	require.Equal(t, "0.1.0", r.Header.Get("X-Prometheus-Remote-Write-Version"))
+
+	compressed, err := ioutil.ReadAll(r.Body)
+	require.NoError(t, err)
+	buf, err := snappy.Decode(nil, compressed)
+	require.NoError(t, err)
+
+	req := &writeRequest{}
+	require.NoError(t, proto.Unmarshal(buf, req))
+	return req
+}
+
+func TestConvert(t *testing.T) {
+	tm := time.Unix(1, 500000000)
+	c := newConverter(false)
+	c.add(testutil.MustMetric(
+		"cpu",
+		map[string]string{"host": "example.org", "cpu-id": "0"},
+		map[string]interface{}{"value": 42.0, "idle": int64(3), "state": "on"},
+		tm,
+	))
+	c.add(testutil.MustMetric(
+		"http_requests_total",
+		map[string]string{},
+		map[string]interface{}{"counter": uint64(10)},
+		tm,
+		telegraf.Counter,
+	))
+
+	requests := c.requests(100)
+	require.Len(t, requests, 1)
+	require.Equal(t, map[string]series{
+		"__name__=cpu,cpu_id=0,host=example.org": {
+			Labels:  map[string]string{"__name__": "cpu", "cpu_id": "0", "host": "example.org"},
+			Samples: []sample{{Value: 42.0, Timestamp: 1500}},
+		},
+		"__name__=cpu_idle,cpu_id=0,host=example.org": {
+			Labels:  map[string]string{"__name__": "cpu_idle", "cpu_id": "0", "host": "example.org"},
+			Samples: []sample{{Value: 3, Timestamp: 1500}},
+		},
+		"__name__=http_requests_total": {
+			Labels:  map[string]string{"__name__": "http_requests_total"},
+			Samples: []sample{{Value: 10, Timestamp: 1500}},
+		},
+	}, flatten(requests[0]))
+}
+
+func TestConvertHistogramAndSummary(t *testing.T) {
+	tm := time.Unix(0, 0)
+	c := newConverter(false)
+	c.add(testutil.MustMetric(
+		"latency",
+		map[string]string{},
+		map[string]interface{}{"0.5": 3.0, "1": 5.0, "sum": 2.5, "count": 6.0},
+		tm,
+		telegraf.Histogram,
+	))
+	c.add(testutil.MustMetric(
+		"rpc",
+		map[string]string{},
+		map[string]interface{}{"0.99": 0.1, "sum": 2.0, "count": 4.0},
+		tm,
+		telegraf.Summary,
+	))
+
+	requests := c.requests(100)
+	require.Len(t, requests, 1)
+	actual := flatten(requests[0])
+	expected := map[string]float64{
+		"__name__=latency_bucket,le=0.5":  3,
+		"__name__=latency_bucket,le=1":    5,
+		"__name__=latency_bucket,le=+Inf": 6,
+		"__name__=latency_sum":            2.5,
+		"__name__=latency_count":          6,
+		"__name__=rpc,quantile=0.99":      0.1,
+		"__name__=rpc_sum":                2,
+		"__name__=rpc_count":              4,
+	}
+	require.Len(t, actual, len(expected))
+	for key, value := range expected {
+		require.Contains(t, actual, key)
+		require.Equal(t, []sample{{Value: value}}, actual[key].Samples, key)
+	}
+}
+
+func TestConvertBatches(t *testing.T) {
+	c := newConverter(false)
+	for i := 2; i >= 0; i-- {
+		c.add(testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"value": float64(i)},
+			time.Unix(int64(i), 0),
+		))
+	}
+
+	requests := c.requests(2)
+	require.Len(t, requests, 2)
+	require.Equal(t, []*sample{{Value: 0, Timestamp: 0}, {Value: 1, Timestamp: 1000}}, requests[0].Timeseries[0].Samples)
+	require.Equal(t, []*sample{{Value: 2, Timestamp: 2000}}, requests[1].Timeseries[0].Samples)
+}
+
+func TestWrite(t *testing.T) {
+	var received *writeRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		require.Equal(t, "tenant", r.Header.Get("X-Scope-OrgID"))
+		received = decodeRequest(t, r)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	plugin := &PrometheusRemoteWrite{
+		URL:         ts.URL,
+		BearerToken: "token",
+		Headers:     map[string]string{"X-Scope-OrgID": "tenant"},
+	}
+	require.NoError(t, plugin.Connect())
+
+	err := plugin.Write([]telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": "example.org"},
+			map[string]interface{}{"value": 42.0},
+			time.Unix(0, 0),
+		),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, received)
+	require.Equal(t, map[string]series{
+		"__name__=cpu,host=example.org": {
+			Labels:  map[string]string{"__name__": "cpu", "host": "example.org"},
+			Samples: []sample{{Value: 42.0}},
+		},
+	}, flatten(received))
+}
+
+func TestWriteBasicAuth(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "username", username)
+		require.Equal(t, "pa$$word", password)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	plugin := &PrometheusRemoteWrite{
+		URL:      ts.URL,
+		Username: "username",
+		Password: "pa$$word",
+	}
+	require.NoError(t, plugin.Connect())
+
+	err := plugin.Write([]telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+	})
+	require.NoError(t, err)
+}
+
+func TestWriteRetry(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	plugin := &PrometheusRemoteWrite{
+		URL:        ts.URL,
+		MaxRetries: 1,
+	}
+	require.NoError(t, plugin.Connect())
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+	}
+
+	// the retry is unsuccessful
+	require.Error(t, plugin.Write(metrics))
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	require.NoError(t, plugin.Write(metrics))
+	require.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
+
+func TestWriteDropsInvalidRequests(t *testing.T) {
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.Error(w, "out of order sample", http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	plugin := &PrometheusRemoteWrite{
+		URL:        ts.URL,
+		MaxRetries: 3,
+	}
+	require.NoError(t, plugin.Connect())
+
+	err := plugin.Write([]telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestWriteResumesAfterFailedRequest(t *testing.T) {
+	var requests int32
+	var received []*writeRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := decodeRequest(t, r)
+		if atomic.AddInt32(&requests, 1) == 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		received = append(received, req)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	plugin := &PrometheusRemoteWrite{
+		URL:               ts.URL,
+		MaxSamplesPerSend: 1,
+	}
+	require.NoError(t, plugin.Connect())
+
+	var metrics []telegraf.Metric
+	for i := 0; i < 3; i++ {
+		metrics = append(metrics, testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"value": float64(i)},
+			time.Unix(int64(i), 0),
+		))
+	}
+
+	// the second request fails after the first one was sent
+	require.Error(t, plugin.Write(metrics))
+	require.Len(t, received, 1)
+
+	// writing the batch again starts with the failed request
+	require.NoError(t, plugin.Write(metrics))
+	require.Equal(t, int32(4), atomic.LoadInt32(&requests))
+	require.Len(t, received, 3)
+	for i, req := range received {
+		require.Equal(t, []*sample{{Value: float64(i), Timestamp: int64(i) * 1000}}, req.Timeseries[0].Samples)
+	}
+
+	// the sent samples are forgotten after a successful write
+	require.NoError(t, plugin.Write(metrics[:1]))
+	require.Len(t, received, 4)
+}
@@ -0,0 +1,43 @@
+package prometheus_remote_write
+
+import (
+	"github.com/golang/protobuf/proto"
+)
+
+// The messages of the Prometheus remote write protocol, see
+// https://github.com/prometheus/prometheus/blob/master/prompb/remote.proto
+
+type writeRequest struct {
+	Timeseries []*timeSeries `protobuf:"bytes,1,rep,name=timeseries" json:"timeseries,omitempty"`
+}
+
+func (m *writeRequest) Reset()         { *m = writeRequest{} }
+func (m *writeRequest) String() string { return proto.CompactTextString(m) }
+func (*writeRequest) ProtoMessage()    {}
+
+type timeSeries struct {
+	Labels  []*label  `protobuf:"bytes,1,rep,name=labels" json:"labels,omitempty"`
+	Samples []*sample `protobuf:"bytes,2,rep,name=samples" json:"samples,omitempty"`
+}
+
+func (m *timeSeries) Reset()         { *m = timeSeries{} }
+func (m *timeSeries) String() string { return proto.CompactTextString(m) }
+func (*timeSeries) ProtoMessage()    {}
+
+type label struct {
+	Name  string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (m *label) Reset()         { *m = label{} }
+func (m *label) String() string { return proto.CompactTextString(m) }
+func (*label) ProtoMessage()    {}
+
+type sample struct {
+	Value     float64 `protobuf:"fixed64,1,opt,name=value,proto3" json:"value,omitempty"`
+	Timestamp int64   `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+}
+
+func (m *sample) Reset()         { *m = sample{} }
+func (m *sample) String() string { return proto.CompactTextString(m) }
+func (*sample) ProtoMessage()    {}
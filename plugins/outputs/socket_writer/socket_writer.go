@@ -1,10 +1,13 @@
 package socket_writer
 
 import (
+	"compress/gzip"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"strings"
+	"time"
 
 	"crypto/tls"
 
@@ -15,14 +18,30 @@ import (
 	"github.com/influxdata/telegraf/plugins/serializers"
 )
 
+const (
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = time.Minute
+)
+
 type SocketWriter struct {
 	Address         string
 	KeepAlivePeriod *internal.Duration
+	WriteTimeout    internal.Duration `toml:"write_timeout"`
+	UDPPayload      internal.Size     `toml:"udp_payload"`
+	ContentEncoding string            `toml:"content_encoding"`
 	tlsint.ClientConfig
 
 	serializers.Serializer
 
 	net.Conn
+
+	writer io.Writer
+	gz     *gzip.Writer
+
+	// backoff is the delay before the next reconnection attempt after a
+	// failed one.
+	backoff     time.Duration
+	nextConnect time.Time
 }
 
 func (sw *SocketWriter) Description() string {
@@ -56,6 +75,17 @@ func (sw *SocketWriter) SampleConfig() string {
   ## Defaults to the OS configuration.
   # keep_alive_period = "5m"
 
+  ## Timeout for writing to the socket, 0 waits indefinitely.
+  # write_timeout = "0s"
+
+  ## Maximum size of the datagrams of UDP and unixgram sockets, multiple
+  ## metrics are packed into each datagram.  0 sends a datagram per metric.
+  # udp_payload = "0B"
+
+  ## Content encoding of TCP and unix sockets, "identity" or "gzip".  With
+  ## gzip a compressed stream is flushed after each write.
+  # content_encoding = "identity"
+
   ## Data format to generate.
   ## Each data format has its own unique set of configuration options, read
   ## more about them here:
@@ -74,6 +104,16 @@ func (sw *SocketWriter) Connect() error {
 		return fmt.Errorf("invalid address: %s", sw.Address)
 	}
 
+	switch sw.ContentEncoding {
+	case "", "identity":
+	case "gzip":
+		if isPacket(spl[0]) {
+			return fmt.Errorf("content_encoding gzip is not supported on %s sockets", spl[0])
+		}
+	default:
+		return fmt.Errorf("invalid content_encoding: %s", sw.ContentEncoding)
+	}
+
 	tlsCfg, err := sw.ClientConfig.TLSConfig()
 	if err != nil {
 		return err
@@ -94,9 +134,42 @@ func (sw *SocketWriter) Connect() error {
 	}
 
 	sw.Conn = c
+	sw.writer = c
+	if sw.ContentEncoding == "gzip" {
+		sw.gz = gzip.NewWriter(c)
+		sw.writer = sw.gz
+	}
+	return nil
+}
+
+// reconnect connects to the address unless a previous attempt failed
+// recently, the delay between attempts is doubled after each failure.
+func (sw *SocketWriter) reconnect() error {
+	now := time.Now()
+	if now.Before(sw.nextConnect) {
+		return fmt.Errorf("reconnecting to %s delayed until %s", sw.Address, sw.nextConnect.Format(time.RFC3339))
+	}
+
+	if err := sw.Connect(); err != nil {
+		sw.backoff *= 2
+		if sw.backoff < minReconnectBackoff {
+			sw.backoff = minReconnectBackoff
+		}
+		if sw.backoff > maxReconnectBackoff {
+			sw.backoff = maxReconnectBackoff
+		}
+		sw.nextConnect = now.Add(sw.backoff)
+		return err
+	}
+	sw.backoff = 0
 	return nil
 }
 
+// isPacket returns true if the network is datagram oriented.
+func isPacket(network string) bool {
+	return strings.HasPrefix(network, "udp") || network == "unixgram"
+}
+
 func (sw *SocketWriter) setKeepAlive(c net.Conn) error {
 	if sw.KeepAlivePeriod == nil {
 		return nil
@@ -120,38 +193,93 @@ func (sw *SocketWriter) setKeepAlive(c net.Conn) error {
 func (sw *SocketWriter) Write(metrics []telegraf.Metric) error {
 	if sw.Conn == nil {
 		// previous write failed with permanent error and socket was closed.
-		if err := sw.Connect(); err != nil {
+		if err := sw.reconnect(); err != nil {
 			return err
 		}
 	}
 
+	if sw.WriteTimeout.Duration > 0 {
+		sw.Conn.SetWriteDeadline(time.Now().Add(sw.WriteTimeout.Duration))
+	}
+
+	network := strings.SplitN(sw.Address, "://", 2)[0]
+	if isPacket(network) && sw.UDPPayload.Size > 0 {
+		return sw.writePackets(metrics)
+	}
+	return sw.writeStream(metrics)
+}
+
+// writeStream writes each metric separately, and flushes the compressed
+// stream if any.
+func (sw *SocketWriter) writeStream(metrics []telegraf.Metric) error {
 	for _, m := range metrics {
 		bs, err := sw.Serialize(m)
 		if err != nil {
 			//TODO log & keep going with remaining metrics
 			return err
 		}
-		if _, err := sw.Conn.Write(bs); err != nil {
+		if _, err := sw.writer.Write(bs); err != nil {
 			//TODO log & keep going with remaining strings
-			if err, ok := err.(net.Error); !ok || !err.Temporary() {
-				// permanent error. close the connection
-				sw.Close()
-				sw.Conn = nil
-			}
+			return sw.writeError(err)
+		}
+	}
+
+	if sw.gz != nil {
+		if err := sw.gz.Flush(); err != nil {
+			return sw.writeError(err)
+		}
+	}
+	return nil
+}
+
+// writePackets packs the metrics into datagrams of at most udp_payload
+// bytes, metrics larger than the payload are sent alone.
+func (sw *SocketWriter) writePackets(metrics []telegraf.Metric) error {
+	payload := int(sw.UDPPayload.Size)
+	buf := make([]byte, 0, payload)
+	for _, m := range metrics {
+		bs, err := sw.Serialize(m)
+		if err != nil {
 			return err
 		}
+		if len(buf) > 0 && len(buf)+len(bs) > payload {
+			if _, err := sw.Conn.Write(buf); err != nil {
+				return sw.writeError(err)
+			}
+			buf = buf[:0]
+		}
+		buf = append(buf, bs...)
 	}
 
+	if len(buf) > 0 {
+		if _, err := sw.Conn.Write(buf); err != nil {
+			return sw.writeError(err)
+		}
+	}
 	return nil
 }
 
+// writeError closes the connection on permanent errors and timeouts, since
+// a partial write may have corrupted the stream.
+func (sw *SocketWriter) writeError(err error) error {
+	if err, ok := err.(net.Error); !ok || !err.Temporary() || err.Timeout() || sw.gz != nil {
+		sw.Close()
+	}
+	return err
+}
+
 // Close closes the connection. Noop if already closed.
 func (sw *SocketWriter) Close() error {
 	if sw.Conn == nil {
 		return nil
 	}
+	if sw.gz != nil {
+		sw.gz.Close()
+		sw.gz = nil
+	}
 	err := sw.Conn.Close()
 	sw.Conn = nil
+	sw.writer = nil
 	return err
 }
 
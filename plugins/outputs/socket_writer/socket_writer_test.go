@@ -3,10 +3,12 @@ package socket_writer
 import (
 	"bufio"
 	"bytes"
+	"compress/gzip"
 	"net"
 	"os"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/testutil"
@@ -187,3 +189,125 @@ func TestSocketWriter_Write_reconnect(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, string(mbsout), string(buf[:n]))
 }
+
+func TestSocketWriter_udpPayload(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	sw := newSocketWriter()
+	sw.Address = "udp://" + listener.LocalAddr().String()
+	sw.UDPPayload.Size = 512
+
+	err = sw.Connect()
+	require.NoError(t, err)
+	defer sw.Close()
+
+	var metrics []telegraf.Metric
+	var expected []byte
+	for i := 0; i < 3; i++ {
+		m := testutil.TestMetric(i, "test")
+		bs, _ := sw.Serialize(m)
+		metrics = append(metrics, m)
+		expected = append(expected, bs...)
+	}
+	require.True(t, len(expected) <= 512)
+
+	err = sw.Write(metrics)
+	require.NoError(t, err)
+
+	buf := make([]byte, 1024)
+	n, _, err := listener.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(buf[:n]))
+
+	// metrics not fitting the payload are sent in separate datagrams
+	sw.UDPPayload.Size = int64(len(expected) - 1)
+	err = sw.Write(metrics)
+	require.NoError(t, err)
+
+	n, _, err = listener.ReadFrom(buf)
+	require.NoError(t, err)
+	bs, _ := sw.Serialize(metrics[2])
+	assert.Equal(t, string(expected[:len(expected)-len(bs)]), string(buf[:n]))
+	n, _, err = listener.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, string(bs), string(buf[:n]))
+}
+
+func TestSocketWriter_gzip(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	sw := newSocketWriter()
+	sw.Address = "tcp://" + listener.Addr().String()
+	sw.ContentEncoding = "gzip"
+	sw.WriteTimeout.Duration = time.Second
+
+	err = sw.Connect()
+	require.NoError(t, err)
+	defer sw.Close()
+
+	lconn, err := listener.Accept()
+	require.NoError(t, err)
+	defer lconn.Close()
+
+	metrics := []telegraf.Metric{testutil.TestMetric(1, "test")}
+	mbsout, _ := sw.Serialize(metrics[0])
+	err = sw.Write(metrics)
+	require.NoError(t, err)
+
+	gz, err := gzip.NewReader(lconn)
+	require.NoError(t, err)
+	scnr := bufio.NewScanner(gz)
+	require.True(t, scnr.Scan())
+	assert.Equal(t, string(mbsout), scnr.Text()+"\n")
+}
+
+func TestSocketWriter_contentEncoding(t *testing.T) {
+	sw := newSocketWriter()
+	sw.Address = "udp://127.0.0.1:8094"
+	sw.ContentEncoding = "gzip"
+	require.Error(t, sw.Connect())
+
+	sw.Address = "tcp://127.0.0.1:8094"
+	sw.ContentEncoding = "br"
+	require.Error(t, sw.Connect())
+}
+
+func TestSocketWriter_reconnectBackoff(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	sw := newSocketWriter()
+	sw.Address = "tcp://" + address
+
+	metrics := []telegraf.Metric{testutil.TestMetric(1, "test")}
+	err = sw.Write(metrics)
+	require.Error(t, err)
+	assert.Equal(t, minReconnectBackoff, sw.backoff)
+
+	// attempts are delayed until the backoff has elapsed
+	err = sw.Write(metrics)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "delayed")
+	assert.Equal(t, minReconnectBackoff, sw.backoff)
+
+	sw.nextConnect = time.Time{}
+	err = sw.Write(metrics)
+	require.Error(t, err)
+	assert.Equal(t, 2*minReconnectBackoff, sw.backoff)
+
+	listener, err = net.Listen("tcp", address)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	sw.nextConnect = time.Time{}
+	err = sw.Write(metrics)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), sw.backoff)
+	sw.Close()
+}
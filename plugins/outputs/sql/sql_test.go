@@ -0,0 +1,315 @@
+package sql
+
+import (
+	gosql "database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// testDriver is an in-memory database understanding the statements of the
+// postgres dialect, inserts failing if a value is "fail".
+type testDriver struct {
+	mu         sync.Mutex
+	statements []string
+	tables     map[string][]string
+	rows       map[string][]map[string]interface{}
+}
+
+var (
+	createRE = regexp.MustCompile(`^CREATE TABLE IF NOT EXISTS "([^"]+)" \((.*)\)$`)
+	columnRE = regexp.MustCompile(`"([^"]+)" [^,]+`)
+	alterRE  = regexp.MustCompile(`^ALTER TABLE "([^"]+)" ADD COLUMN "([^"]+)" `)
+	selectRE = regexp.MustCompile(`^SELECT \* FROM "([^"]+)" WHERE 1=0$`)
+	insertRE = regexp.MustCompile(`^INSERT INTO "([^"]+)" \((.*)\) VALUES`)
+)
+
+var db = &testDriver{}
+
+func init() {
+	gosql.Register("sqltest", db)
+	dialects["sqltest"] = postgresDialect
+}
+
+func (d *testDriver) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements = nil
+	d.tables = make(map[string][]string)
+	d.rows = make(map[string][]map[string]interface{})
+}
+
+func (d *testDriver) Open(name string) (driver.Conn, error) {
+	return &testConn{d: d}, nil
+}
+
+type testConn struct {
+	d       *testDriver
+	pending []func()
+}
+
+func (c *testConn) Prepare(query string) (driver.Stmt, error) {
+	return &testStmt{c: c, query: query}, nil
+}
+
+func (c *testConn) Close() error {
+	return nil
+}
+
+func (c *testConn) Begin() (driver.Tx, error) {
+	c.pending = []func(){}
+	return c, nil
+}
+
+func (c *testConn) Commit() error {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	for _, f := range c.pending {
+		f()
+	}
+	c.pending = nil
+	return nil
+}
+
+func (c *testConn) Rollback() error {
+	c.pending = nil
+	return nil
+}
+
+type testStmt struct {
+	c     *testConn
+	query string
+}
+
+func (s *testStmt) Close() error {
+	return nil
+}
+
+func (s *testStmt) NumInput() int {
+	return -1
+}
+
+func (s *testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	d := s.c.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements = append(d.statements, s.query)
+
+	if m := createRE.FindStringSubmatch(s.query); m != nil {
+		if _, ok := d.tables[m[1]]; !ok {
+			for _, column := range columnRE.FindAllStringSubmatch(m[2], -1) {
+				d.tables[m[1]] = append(d.tables[m[1]], column[1])
+			}
+		}
+		return driver.RowsAffected(0), nil
+	}
+	if m := alterRE.FindStringSubmatch(s.query); m != nil {
+		d.tables[m[1]] = append(d.tables[m[1]], m[2])
+		return driver.RowsAffected(0), nil
+	}
+	if m := insertRE.FindStringSubmatch(s.query); m != nil {
+		columns := strings.Split(m[2], ",")
+		row := make(map[string]interface{})
+		for i, column := range columns {
+			if args[i] == "fail" {
+				return nil, errors.New("insert failed")
+			}
+			if args[i] != nil {
+				row[strings.Trim(column, `"`)] = args[i]
+			}
+		}
+		table := m[1]
+		s.c.pending = append(s.c.pending, func() {
+			d.rows[table] = append(d.rows[table], row)
+		})
+		return driver.RowsAffected(1), nil
+	}
+	return nil, fmt.Errorf("unsupported statement: %s", s.query)
+}
+
+func (s *testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	d := s.c.d
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	m := selectRE.FindStringSubmatch(s.query)
+	if m == nil {
+		return nil, fmt.Errorf("unsupported query: %s", s.query)
+	}
+	columns, ok := d.tables[m[1]]
+	if !ok {
+		return nil, fmt.Errorf("table %q does not exist", m[1])
+	}
+	return &testRows{columns: columns}, nil
+}
+
+type testRows struct {
+	columns []string
+}
+
+func (r *testRows) Columns() []string {
+	return r.columns
+}
+
+func (r *testRows) Close() error {
+	return nil
+}
+
+func (r *testRows) Next(dest []driver.Value) error {
+	return io.EOF
+}
+
+func newTestSQL(t *testing.T) *SQL {
+	db.reset()
+	s := &SQL{
+		Driver:          "sqltest",
+		TimestampColumn: "timestamp",
+	}
+	require.NoError(t, s.Connect())
+	return s
+}
+
+func TestWriteCreatesTables(t *testing.T) {
+	s := newTestSQL(t)
+	defer s.Close()
+
+	tm := time.Unix(0, 0).UTC()
+	err := s.Write([]telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"idle": 42.0}, tm),
+		testutil.MustMetric("mem", map[string]string{}, map[string]interface{}{"free": int64(7), "ok": true}, tm),
+	})
+	require.NoError(t, err)
+
+	require.Contains(t, db.statements,
+		`CREATE TABLE IF NOT EXISTS "cpu" ("timestamp" TIMESTAMP,"host" TEXT,"idle" DOUBLE PRECISION)`)
+	require.Contains(t, db.statements,
+		`CREATE TABLE IF NOT EXISTS "mem" ("timestamp" TIMESTAMP,"free" BIGINT,"ok" BOOLEAN)`)
+	require.Equal(t, []map[string]interface{}{
+		{"timestamp": tm, "host": "a", "idle": 42.0},
+	}, db.rows["cpu"])
+	require.Equal(t, []map[string]interface{}{
+		{"timestamp": tm, "free": int64(7), "ok": true},
+	}, db.rows["mem"])
+}
+
+func TestWriteAddsColumns(t *testing.T) {
+	s := newTestSQL(t)
+	defer s.Close()
+
+	tm := time.Unix(0, 0).UTC()
+	err := s.Write([]telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"idle": 42.0}, tm),
+	})
+	require.NoError(t, err)
+
+	err = s.Write([]telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"user": uint64(3)}, tm),
+	})
+	require.NoError(t, err)
+
+	require.Contains(t, db.statements, `ALTER TABLE "cpu" ADD COLUMN "host" TEXT`)
+	require.Contains(t, db.statements, `ALTER TABLE "cpu" ADD COLUMN "user" BIGINT`)
+	require.Equal(t, []map[string]interface{}{
+		{"timestamp": tm, "idle": 42.0},
+		{"timestamp": tm, "host": "a", "user": int64(3)},
+	}, db.rows["cpu"])
+}
+
+func TestWriteExistingTable(t *testing.T) {
+	s := newTestSQL(t)
+	defer s.Close()
+	db.tables["cpu"] = []string{"timestamp", "idle"}
+
+	err := s.Write([]telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"idle": 42.0}, time.Unix(0, 0)),
+	})
+	require.NoError(t, err)
+	for _, stmt := range db.statements {
+		require.False(t, strings.HasPrefix(stmt, "ALTER"), stmt)
+	}
+	require.Len(t, db.rows["cpu"], 1)
+}
+
+func TestWriteRollback(t *testing.T) {
+	s := newTestSQL(t)
+	defer s.Close()
+
+	tm := time.Unix(0, 0)
+	err := s.Write([]telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"idle": 42.0}, tm),
+		testutil.MustMetric("mem", map[string]string{}, map[string]interface{}{"state": "fail"}, tm),
+	})
+	require.Error(t, err)
+	require.Empty(t, db.rows["cpu"])
+	require.Empty(t, db.rows["mem"])
+}
+
+func TestWriteUnsignedOverflow(t *testing.T) {
+	s := newTestSQL(t)
+	defer s.Close()
+
+	tm := time.Unix(0, 0).UTC()
+	m := testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"big": uint64(math.MaxUint64), "small": uint64(1)}, tm)
+	require.NoError(t, s.Write([]telegraf.Metric{m}))
+
+	// dialects with unsigned columns get the value as a decimal string
+	s.dialect.unsigned = true
+	require.NoError(t, s.Write([]telegraf.Metric{m}))
+
+	require.Equal(t, []map[string]interface{}{
+		{"timestamp": tm, "small": int64(1)},
+		{"timestamp": tm, "big": "18446744073709551615", "small": int64(1)},
+	}, db.rows["cpu"])
+}
+
+func TestWriteTagFieldClash(t *testing.T) {
+	s := newTestSQL(t)
+	defer s.Close()
+
+	tm := time.Unix(0, 0).UTC()
+	err := s.Write([]telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "a", "timestamp": "x"}, map[string]interface{}{"idle": 42.0}, tm),
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"host": 1.0, "timestamp": 2.0, "idle": 43.0}, tm),
+	})
+	require.NoError(t, err)
+
+	require.Contains(t, db.statements,
+		`CREATE TABLE IF NOT EXISTS "cpu" ("timestamp" TIMESTAMP,"host" TEXT,"idle" DOUBLE PRECISION)`)
+	require.Equal(t, []map[string]interface{}{
+		{"timestamp": tm, "host": "a", "idle": 42.0},
+		{"timestamp": tm, "idle": 43.0},
+	}, db.rows["cpu"])
+}
+
+func TestConvertTypes(t *testing.T) {
+	db.reset()
+	s := &SQL{
+		Driver:  "sqltest",
+		Convert: ConvertStruct{Real: "REAL"},
+	}
+	require.NoError(t, s.Connect())
+	defer s.Close()
+
+	err := s.Write([]telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"idle": 42.0}, time.Unix(0, 0)),
+	})
+	require.NoError(t, err)
+	require.Contains(t, db.statements,
+		`CREATE TABLE IF NOT EXISTS "cpu" ("timestamp" TIMESTAMP,"idle" REAL)`)
+}
+
+func TestUnsupportedDriver(t *testing.T) {
+	s := &SQL{Driver: "oracle"}
+	require.Error(t, s.Connect())
+}
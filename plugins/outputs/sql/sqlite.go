@@ -0,0 +1,7 @@
+// +build cgo
+
+package sql
+
+import (
+	_ "github.com/mattn/go-sqlite3"
+)
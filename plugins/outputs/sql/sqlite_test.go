@@ -0,0 +1,64 @@
+// +build cgo
+
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSQLite writes to an in-memory SQLite database, checking the statements
+// against a real database.
+func TestSQLite(t *testing.T) {
+	s := &SQL{
+		Driver:          "sqlite3",
+		DataSourceName:  "file:telegraf?mode=memory&cache=shared",
+		TimestampColumn: "timestamp",
+	}
+	require.NoError(t, s.Connect())
+	defer s.Close()
+
+	tm := time.Unix(1529426400, 0).UTC()
+	err := s.Write([]telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"idle": 42.5}, tm),
+	})
+	require.NoError(t, err)
+
+	// new tags and fields add columns
+	err = s.Write([]telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{"host": "b", "cpu": "cpu0"},
+			map[string]interface{}{"idle": 41.5, "user": int64(3), "ok": true, "state": "up"}, tm.Add(time.Second)),
+	})
+	require.NoError(t, err)
+
+	rows, err := s.db.Query(`SELECT "timestamp","host","cpu","idle","user","ok","state" FROM "cpu" ORDER BY "timestamp"`)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	type row struct {
+		timestamp time.Time
+		host      string
+		cpu       *string
+		idle      float64
+		user      *int64
+		ok        *bool
+		state     *string
+	}
+	var actual []row
+	for rows.Next() {
+		var r row
+		require.NoError(t, rows.Scan(&r.timestamp, &r.host, &r.cpu, &r.idle, &r.user, &r.ok, &r.state))
+		actual = append(actual, r)
+	}
+	require.NoError(t, rows.Err())
+
+	cpu, user, ok, state := "cpu0", int64(3), true, "up"
+	require.Equal(t, []row{
+		{timestamp: tm, host: "a", idle: 42.5},
+		{timestamp: tm.Add(time.Second), host: "b", cpu: &cpu, idle: 41.5, user: &user, ok: &ok, state: &state},
+	}, actual)
+}
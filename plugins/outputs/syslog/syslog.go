@@ -0,0 +1,234 @@
+package syslog
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	tlsint "github.com/influxdata/telegraf/internal/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+type Syslog struct {
+	Address             string
+	KeepAlivePeriod     *internal.Duration `toml:"keep_alive_period"`
+	DefaultSdid         string             `toml:"default_sdid"`
+	DefaultSeverityCode int64              `toml:"default_severity_code"`
+	DefaultFacilityCode int64              `toml:"default_facility_code"`
+	DefaultAppname      string             `toml:"default_appname"`
+	Sdids               []string           `toml:"sdids"`
+	Separator           string             `toml:"sdparam_separator"`
+	Framing             string             `toml:"framing"`
+	Trailer             string             `toml:"trailer"`
+	tlsint.ClientConfig
+
+	net.Conn
+	mapper *mapper
+}
+
+var sampleConfig = `
+  ## URL to connect to
+  ## ex: address = "tcp://127.0.0.1:8094"
+  ## ex: address = "tcp4://127.0.0.1:8094"
+  ## ex: address = "tcp6://127.0.0.1:8094"
+  ## ex: address = "tcp6://[2001:db8::1]:8094"
+  ## ex: address = "udp://127.0.0.1:8094"
+  ## ex: address = "udp4://127.0.0.1:8094"
+  ## ex: address = "udp6://127.0.0.1:8094"
+  address = "tcp://127.0.0.1:6514"
+
+  ## Optional TLS Config
+  # tls_ca = "/etc/telegraf/ca.pem"
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Use TLS but skip chain & host verification
+  # insecure_skip_verify = false
+
+  ## Period between keep alive probes.
+  ## Only applies to TCP sockets.
+  ## 0 disables keep alive probes.
+  ## Defaults to the OS configuration.
+  # keep_alive_period = "5m"
+
+  ## The framing technique with which messages are transported, messages
+  ## are framed by their length with "octet-counting" (RFC5425#section-4.3.1,
+  ## RFC6587#section-3.4.1), or terminated by the trailer with
+  ## "non-transparent" (RFC6587#section-3.4.2).
+  ## Only applies to stream sockets (e.g. TCP).
+  # framing = "octet-counting"
+
+  ## The trailer of messages with non-transparent framing, "LF" or "NUL".
+  # trailer = "LF"
+
+  ## SD-PARAMs settings
+  ## Syslog messages can contain key/value pairs within zero or more
+  ## structured data sections.  For each SD-ID in sdids, tags and fields
+  ## named with the SD-ID, the separator and the name of the SD-PARAM are
+  ## added to its element.  Other tags and fields are added to the element
+  ## of the default_sdid, if set.
+  # sdparam_separator = "_"
+  # default_sdid = "default@32473"
+  # sdids = ["foo@123", "bar@456"]
+
+  ## Default severity code, used if the metric has no severity_code field.
+  ## 0 (emergency) to 7 (debug).
+  # default_severity_code = 5
+
+  ## Default facility code, used if the metric has no facility_code field.
+  ## 0 (kernel) to 23 (local7).
+  # default_facility_code = 1
+
+  ## Default APP-NAME, used if the metric has no appname tag.
+  # default_appname = "Telegraf"
+`
+
+func (s *Syslog) Description() string {
+	return "Configuration for Syslog server to send metrics to"
+}
+
+func (s *Syslog) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Syslog) Connect() error {
+	spl := strings.SplitN(s.Address, "://", 2)
+	if len(spl) != 2 {
+		return fmt.Errorf("invalid address: %s", s.Address)
+	}
+
+	switch s.Framing {
+	case "", "octet-counting", "non-transparent":
+	default:
+		return fmt.Errorf("invalid framing %q", s.Framing)
+	}
+	switch strings.ToUpper(s.Trailer) {
+	case "", "LF", "NUL":
+	default:
+		return fmt.Errorf("invalid trailer %q", s.Trailer)
+	}
+	if s.DefaultSeverityCode < 0 || s.DefaultSeverityCode > 7 {
+		return fmt.Errorf("invalid default_severity_code %d", s.DefaultSeverityCode)
+	}
+	if s.DefaultFacilityCode < 0 || s.DefaultFacilityCode > 23 {
+		return fmt.Errorf("invalid default_facility_code %d", s.DefaultFacilityCode)
+	}
+
+	s.mapper = &mapper{
+		defaultSdid:         s.DefaultSdid,
+		sdids:               s.Sdids,
+		separator:           s.Separator,
+		defaultSeverityCode: s.DefaultSeverityCode,
+		defaultFacilityCode: s.DefaultFacilityCode,
+		defaultAppname:      s.DefaultAppname,
+	}
+
+	tlsCfg, err := s.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	var c net.Conn
+	if tlsCfg == nil {
+		c, err = net.Dial(spl[0], spl[1])
+	} else {
+		c, err = tls.Dial(spl[0], spl[1], tlsCfg)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.setKeepAlive(c); err != nil {
+		log.Printf("unable to configure keep alive (%s): %s", s.Address, err)
+	}
+
+	s.Conn = c
+	return nil
+}
+
+func (s *Syslog) setKeepAlive(c net.Conn) error {
+	if s.KeepAlivePeriod == nil {
+		return nil
+	}
+	tcpc, ok := c.(*net.TCPConn)
+	if !ok {
+		return fmt.Errorf("cannot set keep alive on a %s socket", strings.SplitN(s.Address, "://", 2)[0])
+	}
+	if s.KeepAlivePeriod.Duration == 0 {
+		return tcpc.SetKeepAlive(false)
+	}
+	if err := tcpc.SetKeepAlive(true); err != nil {
+		return err
+	}
+	return tcpc.SetKeepAlivePeriod(s.KeepAlivePeriod.Duration)
+}
+
+// Write sends a message for each metric.
+// If an error is encountered, it is up to the caller to retry the same write again later.
+// Not parallel safe.
+func (s *Syslog) Write(metrics []telegraf.Metric) error {
+	if s.Conn == nil {
+		// previous write failed with permanent error and socket was closed.
+		if err := s.Connect(); err != nil {
+			return err
+		}
+	}
+
+	for _, metric := range metrics {
+		msg := s.frame(s.mapper.mapMetric(metric))
+		if _, err := s.Conn.Write(msg); err != nil {
+			if err, ok := err.(net.Error); !ok || !err.Temporary() {
+				// permanent error. close the connection
+				s.Close()
+				s.Conn = nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// frame frames the message for stream sockets, messages over packet sockets
+// are sent unframed in a datagram each.
+func (s *Syslog) frame(msg []byte) []byte {
+	if _, ok := s.Conn.(net.PacketConn); ok {
+		return msg
+	}
+
+	if s.Framing == "non-transparent" {
+		if strings.ToUpper(s.Trailer) == "NUL" {
+			return append(msg, 0)
+		}
+		return append(msg, '\n')
+	}
+	return append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+}
+
+// Close closes the connection. Noop if already closed.
+func (s *Syslog) Close() error {
+	if s.Conn == nil {
+		return nil
+	}
+	err := s.Conn.Close()
+	s.Conn = nil
+	return err
+}
+
+func newSyslog() *Syslog {
+	return &Syslog{
+		Framing:             "octet-counting",
+		Trailer:             "LF",
+		Separator:           "_",
+		DefaultSeverityCode: 5, // notice
+		DefaultFacilityCode: 1, // user-level
+		DefaultAppname:      "Telegraf",
+	}
+}
+
+func init() {
+	outputs.Add("syslog", func() telegraf.Output { return newSyslog() })
+}
@@ -0,0 +1,246 @@
+package syslog
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// rfc5424Time is the timestamp format of messages, RFC3339 with
+// microseconds.
+const rfc5424Time = "2006-01-02T15:04:05.000000Z07:00"
+
+// reserved are the tags and fields mapped to the header of a message, as
+// created by the syslog input.
+var reserved = map[string]bool{
+	"severity":      true,
+	"facility":      true,
+	"severity_code": true,
+	"facility_code": true,
+	"version":       true,
+	"timestamp":     true,
+	"hostname":      true,
+	"source":        true,
+	"host":          true,
+	"appname":       true,
+	"procid":        true,
+	"msgid":         true,
+	"message":       true,
+}
+
+// mapper converts metrics to messages in the format of RFC5424.
+type mapper struct {
+	defaultSdid         string
+	sdids               []string
+	separator           string
+	defaultSeverityCode int64
+	defaultFacilityCode int64
+	defaultAppname      string
+}
+
+// element is a structured data element.
+type element struct {
+	id     string
+	params [][2]string
+}
+
+// mapMetric returns the message of a metric:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+func (sm *mapper) mapMetric(metric telegraf.Metric) []byte {
+	severity := sm.defaultSeverityCode
+	if v, ok := intValue(metric, "severity_code"); ok && v >= 0 && v <= 7 {
+		severity = v
+	}
+	facility := sm.defaultFacilityCode
+	if v, ok := intValue(metric, "facility_code"); ok && v >= 0 && v <= 23 {
+		facility = v
+	}
+
+	timestamp := metric.Time()
+	if v, ok := intValue(metric, "timestamp"); ok {
+		timestamp = time.Unix(0, v)
+	}
+
+	hostname := ""
+	for _, key := range []string{"hostname", "source", "host"} {
+		if v, ok := metric.GetTag(key); ok && v != "" {
+			hostname = v
+			break
+		}
+	}
+	appname, ok := metric.GetTag("appname")
+	if !ok || appname == "" {
+		appname = sm.defaultAppname
+	}
+	procid, _ := stringValue(metric, "procid")
+	msgid, ok := stringValue(metric, "msgid")
+	if !ok || msgid == "" {
+		msgid = metric.Name()
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<%d>1 %s %s %s %s %s ",
+		facility*8+severity,
+		timestamp.UTC().Format(rfc5424Time),
+		headerValue(hostname, 255),
+		headerValue(appname, 48),
+		headerValue(procid, 128),
+		headerValue(msgid, 32))
+
+	elements := sm.structuredData(metric)
+	if len(elements) == 0 {
+		buf.WriteByte('-')
+	}
+	for _, e := range elements {
+		buf.WriteByte('[')
+		buf.WriteString(e.id)
+		for _, p := range e.params {
+			fmt.Fprintf(&buf, ` %s="%s"`, p[0], escapeParamValue(p[1]))
+		}
+		buf.WriteByte(']')
+	}
+
+	if msg, ok := stringValue(metric, "message"); ok && msg != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(msg)
+	}
+	return buf.Bytes()
+}
+
+// structuredData returns the elements of the sdids, with the params of the
+// tags and fields prefixed by the sdid and the separator, and the element of
+// the default sdid with the other tags and fields.
+func (sm *mapper) structuredData(metric telegraf.Metric) []element {
+	values := make(map[string]string)
+	for _, tag := range metric.TagList() {
+		values[tag.Key] = tag.Value
+	}
+	for _, field := range metric.FieldList() {
+		values[field.Key] = formatValue(field.Value)
+	}
+
+	var elements []element
+	for _, sdid := range sm.sdids {
+		_, present := values[sdid]
+		delete(values, sdid)
+
+		e := element{id: sdName(sdid)}
+		prefix := sdid + sm.separator
+		for _, key := range sortedKeys(values) {
+			if strings.HasPrefix(key, prefix) {
+				e.params = append(e.params, [2]string{sdName(key[len(prefix):]), values[key]})
+				delete(values, key)
+			}
+		}
+		if present || len(e.params) > 0 {
+			elements = append(elements, e)
+		}
+	}
+
+	if sm.defaultSdid != "" {
+		e := element{id: sdName(sm.defaultSdid)}
+		for _, key := range sortedKeys(values) {
+			if !reserved[key] {
+				e.params = append(e.params, [2]string{sdName(key), values[key]})
+			}
+		}
+		if len(e.params) > 0 {
+			elements = append(elements, e)
+		}
+	}
+	return elements
+}
+
+func intValue(metric telegraf.Metric, key string) (int64, bool) {
+	v, ok := metric.GetField(key)
+	if !ok {
+		return 0, false
+	}
+	switch v := v.(type) {
+	case int64:
+		return v, true
+	case uint64:
+		return int64(v), true
+	case float64:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func stringValue(metric telegraf.Metric, key string) (string, bool) {
+	v, ok := metric.GetField(key)
+	if !ok {
+		return "", false
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// headerValue returns the value of a header field, printable ASCII without
+// spaces of at most max characters, or "-" if empty.
+func headerValue(s string, max int) string {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s) && len(b) < max; i++ {
+		if s[i] > ' ' && s[i] <= '~' {
+			b = append(b, s[i])
+		}
+	}
+	if len(b) == 0 {
+		return "-"
+	}
+	return string(b)
+}
+
+// sdName returns a valid SD-ID or PARAM-NAME, replacing invalid characters
+// with underscores.
+func sdName(s string) string {
+	b := []byte(s)
+	if len(b) > 32 {
+		b = b[:32]
+	}
+	for i, c := range b {
+		if c <= ' ' || c > '~' || c == '=' || c == ']' || c == '"' {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+var paramValueReplacer = strings.NewReplacer(`"`, `\"`, `\`, `\\`, `]`, `\]`)
+
+func escapeParamValue(s string) string {
+	return paramValueReplacer.Replace(s)
+}
@@ -0,0 +1,88 @@
+package syslog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMapper() *mapper {
+	s := newSyslog()
+	return &mapper{
+		separator:           s.Separator,
+		defaultSeverityCode: s.DefaultSeverityCode,
+		defaultFacilityCode: s.DefaultFacilityCode,
+		defaultAppname:      s.DefaultAppname,
+	}
+}
+
+func TestMapMetricDefaults(t *testing.T) {
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{"host": "example.org"},
+		map[string]interface{}{"usage_idle": 98.5},
+		time.Date(2018, 6, 1, 12, 0, 0, 1500, time.UTC),
+	)
+
+	sm := newTestMapper()
+	require.Equal(t,
+		"<13>1 2018-06-01T12:00:00.000001Z example.org Telegraf - cpu -",
+		string(sm.mapMetric(m)))
+}
+
+func TestMapMetricSyslogInput(t *testing.T) {
+	m := testutil.MustMetric(
+		"syslog",
+		map[string]string{
+			"severity": "notice",
+			"facility": "daemon",
+			"hostname": "web1",
+			"appname":  "someservice",
+			"source":   "127.0.0.1",
+		},
+		map[string]interface{}{
+			"version":       int64(1),
+			"severity_code": int64(5),
+			"facility_code": int64(3),
+			"timestamp":     time.Unix(1456029177, 0).UnixNano(),
+			"procid":        "2341",
+			"msgid":         "2",
+			"message":       `"GET /v1/ok HTTP/1.1" 200 145`,
+			"origin":        true,
+			"meta":          true,
+			"meta_sequence": "14125553",
+			"meta_service":  "some]service",
+		},
+		time.Unix(0, 0),
+	)
+
+	sm := newTestMapper()
+	sm.sdids = []string{"meta", "origin"}
+	require.Equal(t,
+		`<29>1 2016-02-21T04:32:57.000000Z web1 someservice 2341 2 [meta sequence="14125553" service="some\]service"][origin] "GET /v1/ok HTTP/1.1" 200 145`,
+		string(sm.mapMetric(m)))
+}
+
+func TestMapMetricDefaultSdid(t *testing.T) {
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{"cpu": "cpu0", "hostname": "example.org"},
+		map[string]interface{}{"usage_idle": 98.5, "foo@123_bar": "baz", "severity_code": int64(3)},
+		time.Unix(0, 0),
+	)
+
+	sm := newTestMapper()
+	sm.defaultSdid = "default@32473"
+	sm.sdids = []string{"foo@123"}
+	require.Equal(t,
+		`<11>1 1970-01-01T00:00:00.000000Z example.org Telegraf - cpu [foo@123 bar="baz"][default@32473 cpu="cpu0" usage_idle="98.5"]`,
+		string(sm.mapMetric(m)))
+}
+
+func TestHeaderValue(t *testing.T) {
+	require.Equal(t, "-", headerValue("", 48))
+	require.Equal(t, "ab", headerValue("a b", 48))
+	require.Equal(t, "abc", headerValue("abcdef", 3))
+}
@@ -0,0 +1,109 @@
+package syslog
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteOctetCounting(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	s := newSyslog()
+	s.Address = "tcp://" + listener.Addr().String()
+	require.NoError(t, s.Connect())
+	defer s.Close()
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	m := testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+	require.NoError(t, s.Write([]telegraf.Metric{m, m}))
+
+	expected := "<13>1 1970-01-01T00:00:00.000000Z - Telegraf - cpu -"
+	buf := make([]byte, 2*(len(expected)+3))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	require.Equal(t, "52 "+expected+"52 "+expected, string(buf))
+}
+
+func TestWriteNonTransparent(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	s := newSyslog()
+	s.Address = "tcp://" + listener.Addr().String()
+	s.Framing = "non-transparent"
+	require.NoError(t, s.Connect())
+	defer s.Close()
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	m := testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+	require.NoError(t, s.Write([]telegraf.Metric{m}))
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "<13>1 1970-01-01T00:00:00.000000Z - Telegraf - cpu -\n", line)
+}
+
+func TestWriteUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+
+	s := newSyslog()
+	s.Address = "udp://" + pc.LocalAddr().String()
+	require.NoError(t, s.Connect())
+	defer s.Close()
+
+	m := testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+	require.NoError(t, s.Write([]telegraf.Metric{m}))
+
+	buf := make([]byte, 1024)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	require.NoError(t, err)
+	require.Equal(t, "<13>1 1970-01-01T00:00:00.000000Z - Telegraf - cpu -", string(buf[:n]))
+}
+
+func TestWriteReconnect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	s := newSyslog()
+	s.Address = "tcp://" + listener.Addr().String()
+	require.NoError(t, s.Connect())
+	s.Close()
+
+	m := testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+	require.NoError(t, s.Write([]telegraf.Metric{m}))
+	require.NotNil(t, s.Conn)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	s := newSyslog()
+	s.Address = "tcp://127.0.0.1:0"
+	s.Framing = "none"
+	require.Error(t, s.Connect())
+
+	s = newSyslog()
+	s.Address = "tcp://127.0.0.1:0"
+	s.DefaultSeverityCode = 8
+	require.Error(t, s.Connect())
+}
@@ -0,0 +1,265 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+)
+
+type Parser struct {
+	MetricName        string
+	HeaderRowCount    int
+	SkipRows          int
+	SkipColumns       int
+	Delimiter         string
+	Comment           string
+	TrimSpace         bool
+	ColumnNames       []string
+	ColumnTypes       []string
+	TagColumns        []string
+	MeasurementColumn string
+	TimestampColumn   string
+	TimestampFormat   string
+	DefaultTags       map[string]string
+	TimeFunc          func() time.Time
+}
+
+func (p *Parser) compile(r io.Reader) (*csv.Reader, error) {
+	csvReader := csv.NewReader(r)
+	// ensures that the reader reads records of different lengths without an error
+	csvReader.FieldsPerRecord = -1
+	if p.Delimiter != "" {
+		runes := []rune(p.Delimiter)
+		if len(runes) != 1 {
+			return nil, fmt.Errorf("csv_delimiter must be a single character, got: %s", p.Delimiter)
+		}
+		csvReader.Comma = runes[0]
+	}
+	if p.Comment != "" {
+		runes := []rune(p.Comment)
+		if len(runes) != 1 {
+			return nil, fmt.Errorf("csv_comment must be a single character, got: %s", p.Comment)
+		}
+		csvReader.Comment = runes[0]
+	}
+	csvReader.TrimLeadingSpace = p.TrimSpace
+	return csvReader, nil
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	r := bytes.NewReader(buf)
+	csvReader, err := p.compile(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// skip first rows
+	for i := 0; i < p.SkipRows; i++ {
+		_, err := csvReader.Read()
+		if err == io.EOF {
+			return []telegraf.Metric{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// if there is a header and no column names were configured, the column
+	// names are built by concatenating the values of each header row.
+	columnNames := p.ColumnNames
+	if p.HeaderRowCount > 0 {
+		headerNames := []string{}
+		for i := 0; i < p.HeaderRowCount; i++ {
+			header, err := csvReader.Read()
+			if err == io.EOF {
+				return []telegraf.Metric{}, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			// concatenate header names
+			for j := range header {
+				name := header[j]
+				if p.TrimSpace {
+					name = strings.Trim(name, " ")
+				}
+				if len(headerNames) <= j {
+					headerNames = append(headerNames, name)
+				} else {
+					headerNames[j] = headerNames[j] + name
+				}
+			}
+		}
+		if len(columnNames) == 0 {
+			columnNames = headerNames[min(p.SkipColumns, len(headerNames)):]
+		}
+	}
+
+	if len(columnNames) == 0 {
+		return nil, fmt.Errorf("csv_column_names must be specified when csv_header_row_count is 0")
+	}
+
+	table, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := make([]telegraf.Metric, 0, len(table))
+	for _, record := range table {
+		m, err := p.parseRecord(record, columnNames)
+		if err != nil {
+			return metrics, err
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
+
+// ParseLine does not use any information in header and assumes
+// csv_column_names is set.
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	if len(p.ColumnNames) == 0 {
+		return nil, fmt.Errorf("csv_column_names must be specified to parse a single line")
+	}
+
+	r := bytes.NewReader([]byte(line))
+	csvReader, err := p.compile(r)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := csvReader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("can not parse the line: %s, for data format: csv", line)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p.parseRecord(record, p.ColumnNames)
+}
+
+func (p *Parser) parseRecord(record []string, columnNames []string) (telegraf.Metric, error) {
+	recordFields := make(map[string]string)
+	fields := make(map[string]interface{})
+
+	// skip columns in record
+	record = record[min(p.SkipColumns, len(record)):]
+
+outer:
+	for i, fieldName := range columnNames {
+		if i >= len(record) {
+			break
+		}
+
+		value := record[i]
+		if p.TrimSpace {
+			value = strings.Trim(value, " ")
+		}
+		if value == "" {
+			continue
+		}
+		recordFields[fieldName] = value
+
+		for _, tagName := range p.TagColumns {
+			if tagName == fieldName {
+				continue outer
+			}
+		}
+		if fieldName == p.MeasurementColumn || fieldName == p.TimestampColumn {
+			continue
+		}
+
+		// Try explicit conversion only when column types is defined.
+		if len(p.ColumnTypes) > 0 {
+			if i >= len(p.ColumnTypes) {
+				return nil, fmt.Errorf("column type: no type configured for column %q", fieldName)
+			}
+
+			var val interface{}
+			var err error
+			switch p.ColumnTypes[i] {
+			case "int":
+				val, err = strconv.ParseInt(value, 10, 64)
+			case "float":
+				val, err = strconv.ParseFloat(value, 64)
+			case "bool":
+				val, err = strconv.ParseBool(value)
+			case "string":
+				val = value
+			default:
+				return nil, fmt.Errorf("column type: unknown type %q", p.ColumnTypes[i])
+			}
+			if err != nil {
+				return nil, fmt.Errorf("column type: parse %q of column %q as %s: %v",
+					value, fieldName, p.ColumnTypes[i], err)
+			}
+			fields[fieldName] = val
+			continue
+		}
+
+		// attempt type conversions
+		if iValue, err := strconv.ParseInt(value, 10, 64); err == nil {
+			fields[fieldName] = iValue
+		} else if fValue, err := strconv.ParseFloat(value, 64); err == nil {
+			fields[fieldName] = fValue
+		} else if bValue, err := strconv.ParseBool(value); err == nil {
+			fields[fieldName] = bValue
+		} else {
+			fields[fieldName] = value
+		}
+	}
+
+	tags := make(map[string]string)
+	for k, v := range p.DefaultTags {
+		tags[k] = v
+	}
+	for _, tagName := range p.TagColumns {
+		if value, ok := recordFields[tagName]; ok {
+			tags[tagName] = value
+		}
+	}
+
+	// will default to plugin name
+	measurementName := p.MetricName
+	if value, ok := recordFields[p.MeasurementColumn]; ok && p.MeasurementColumn != "" {
+		measurementName = value
+	}
+
+	metricTime := p.TimeFunc()
+	if p.TimestampColumn != "" {
+		value, ok := recordFields[p.TimestampColumn]
+		if !ok {
+			return nil, fmt.Errorf("timestamp column %q is missing or empty", p.TimestampColumn)
+		}
+		if p.TimestampFormat == "" {
+			return nil, fmt.Errorf("csv_timestamp_format must be specified if csv_timestamp_column is specified")
+		}
+
+		var err error
+		metricTime, err = internal.ParseTimestamp(value, p.TimestampFormat)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return metric.New(measurementName, tags, fields, metricTime)
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
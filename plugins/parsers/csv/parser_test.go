@@ -0,0 +1,344 @@
+package csv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var DefaultTime = func() time.Time {
+	return time.Unix(3600, 0)
+}
+
+func TestBasicCSV(t *testing.T) {
+	p := Parser{
+		ColumnNames: []string{"first", "second", "third"},
+		TagColumns:  []string{"third"},
+		TimeFunc:    DefaultTime,
+	}
+
+	_, err := p.ParseLine("1.4,true,hi")
+	require.NoError(t, err)
+}
+
+func TestHeaderConcatenationCSV(t *testing.T) {
+	p := Parser{
+		HeaderRowCount:    2,
+		MeasurementColumn: "3",
+		TimeFunc:          DefaultTime,
+	}
+	testCSV := `first,second
+1,2,3
+3.4,70,test_name`
+
+	metrics, err := p.Parse([]byte(testCSV))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "test_name", metrics[0].Name())
+}
+
+func TestHeaderOverride(t *testing.T) {
+	p := Parser{
+		HeaderRowCount:    1,
+		ColumnNames:       []string{"first", "second", "third"},
+		MeasurementColumn: "third",
+		TimeFunc:          DefaultTime,
+	}
+	testCSV := `line1,line2,line3
+3.4,70,test_name`
+	metrics, err := p.Parse([]byte(testCSV))
+	require.NoError(t, err)
+	assert.Equal(t, "test_name", metrics[0].Name())
+}
+
+func TestTimestamp(t *testing.T) {
+	p := Parser{
+		HeaderRowCount:    1,
+		ColumnNames:       []string{"first", "second", "third"},
+		MeasurementColumn: "third",
+		TimestampColumn:   "first",
+		TimestampFormat:   "02/01/06 03:04:05 PM",
+		TimeFunc:          DefaultTime,
+	}
+	testCSV := `line1,line2,line3
+23/05/09 04:05:06 PM,70,test_name
+07/11/09 04:05:06 PM,80,test_name2`
+	metrics, err := p.Parse([]byte(testCSV))
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+
+	assert.Equal(t, int64(1243094706000000000), metrics[0].Time().UnixNano())
+	assert.Equal(t, int64(1257609906000000000), metrics[1].Time().UnixNano())
+	assert.False(t, metrics[0].HasField("first"))
+}
+
+func TestTimestampUnix(t *testing.T) {
+	p := Parser{
+		ColumnNames:     []string{"time", "value"},
+		TimestampColumn: "time",
+		TimestampFormat: "unix_ms",
+		TimeFunc:        DefaultTime,
+	}
+	m, err := p.ParseLine("1243094706123,42")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1243094706123000000), m.Time().UnixNano())
+}
+
+func TestTimestampError(t *testing.T) {
+	p := Parser{
+		HeaderRowCount:    1,
+		ColumnNames:       []string{"first", "second", "third"},
+		MeasurementColumn: "third",
+		TimestampColumn:   "first",
+		TimeFunc:          DefaultTime,
+	}
+	testCSV := `line1,line2,line3
+23/05/09 04:05:06 PM,70,test_name
+07/11/09 04:05:06 PM,80,test_name2`
+	_, err := p.Parse([]byte(testCSV))
+	require.Error(t, err)
+}
+
+func TestQuotedCharacter(t *testing.T) {
+	p := Parser{
+		HeaderRowCount:    1,
+		ColumnNames:       []string{"first", "second", "third"},
+		MeasurementColumn: "third",
+		TimeFunc:          DefaultTime,
+	}
+
+	testCSV := `line1,line2,line3
+"3,4",70,test_name`
+	metrics, err := p.Parse([]byte(testCSV))
+	require.NoError(t, err)
+	assert.Equal(t, "3,4", metrics[0].Fields()["first"])
+}
+
+func TestDelimiter(t *testing.T) {
+	p := Parser{
+		HeaderRowCount:    1,
+		Delimiter:         "%",
+		ColumnNames:       []string{"first", "second", "third"},
+		MeasurementColumn: "third",
+		TimeFunc:          DefaultTime,
+	}
+
+	testCSV := `line1%line2%line3
+3,4%70%test_name`
+	metrics, err := p.Parse([]byte(testCSV))
+	require.NoError(t, err)
+	assert.Equal(t, "3,4", metrics[0].Fields()["first"])
+}
+
+func TestValueConversion(t *testing.T) {
+	p := Parser{
+		HeaderRowCount: 0,
+		Delimiter:      ",",
+		ColumnNames:    []string{"first", "second", "third", "fourth"},
+		MetricName:     "test_value",
+		TimeFunc:       DefaultTime,
+	}
+	testCSV := `3.3,4,true,hello`
+
+	metrics, err := p.Parse([]byte(testCSV))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+
+	assert.Equal(t, "test_value", metrics[0].Name())
+	assert.Equal(t, map[string]interface{}{
+		"first":  3.3,
+		"second": int64(4),
+		"third":  true,
+		"fourth": "hello",
+	}, metrics[0].Fields())
+	assert.Equal(t, DefaultTime(), metrics[0].Time())
+}
+
+func TestColumnTypes(t *testing.T) {
+	p := Parser{
+		ColumnNames: []string{"first", "second", "third", "fourth"},
+		ColumnTypes: []string{"float", "string", "int", "bool"},
+		MetricName:  "test_value",
+		TimeFunc:    DefaultTime,
+	}
+
+	m, err := p.ParseLine("3,4,5,true")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"first":  3.0,
+		"second": "4",
+		"third":  int64(5),
+		"fourth": true,
+	}, m.Fields())
+
+	_, err = p.ParseLine("3,4,five,true")
+	require.Error(t, err)
+}
+
+func TestTagColumnsAndDefaultTags(t *testing.T) {
+	p := Parser{
+		ColumnNames: []string{"host", "region", "value"},
+		TagColumns:  []string{"host", "region"},
+		DefaultTags: map[string]string{"source": "export"},
+		MetricName:  "cpu",
+		TimeFunc:    DefaultTime,
+	}
+
+	m, err := p.ParseLine("server01,us-east,42")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"host":   "server01",
+		"region": "us-east",
+		"source": "export",
+	}, m.Tags())
+	assert.Equal(t, map[string]interface{}{"value": int64(42)}, m.Fields())
+}
+
+func TestSkipComment(t *testing.T) {
+	p := Parser{
+		HeaderRowCount: 0,
+		Comment:        "#",
+		ColumnNames:    []string{"first", "second", "third", "fourth"},
+		MetricName:     "test_value",
+		TimeFunc:       DefaultTime,
+	}
+	testCSV := `#3.3,4,true,hello
+4,9.9,true,name_this`
+
+	metrics, err := p.Parse([]byte(testCSV))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, map[string]interface{}{
+		"first":  int64(4),
+		"second": 9.9,
+		"third":  true,
+		"fourth": "name_this",
+	}, metrics[0].Fields())
+}
+
+func TestTrimSpace(t *testing.T) {
+	p := Parser{
+		HeaderRowCount: 0,
+		TrimSpace:      true,
+		ColumnNames:    []string{"first", "second", "third", "fourth"},
+		MetricName:     "test_value",
+		TimeFunc:       DefaultTime,
+	}
+	testCSV := ` 3.3, 4,    true,hello`
+
+	metrics, err := p.Parse([]byte(testCSV))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"first":  3.3,
+		"second": int64(4),
+		"third":  true,
+		"fourth": "hello",
+	}, metrics[0].Fields())
+}
+
+func TestSkipRows(t *testing.T) {
+	p := Parser{
+		HeaderRowCount:    1,
+		SkipRows:          1,
+		TagColumns:        []string{"line1"},
+		MeasurementColumn: "line3",
+		TimeFunc:          DefaultTime,
+	}
+	testCSV := `garbage nonsense
+line1,line2,line3
+hello,80,test_name2`
+
+	metrics, err := p.Parse([]byte(testCSV))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "test_name2", metrics[0].Name())
+	assert.Equal(t, map[string]string{"line1": "hello"}, metrics[0].Tags())
+	assert.Equal(t, map[string]interface{}{"line2": int64(80)}, metrics[0].Fields())
+}
+
+func TestSkipColumns(t *testing.T) {
+	p := Parser{
+		SkipColumns: 1,
+		ColumnNames: []string{"line1", "line2"},
+		TimeFunc:    DefaultTime,
+	}
+	testCSV := `hello,80,test_name`
+
+	metrics, err := p.Parse([]byte(testCSV))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"line1": int64(80),
+		"line2": "test_name",
+	}, metrics[0].Fields())
+}
+
+func TestSkipColumnsWithHeader(t *testing.T) {
+	p := Parser{
+		SkipColumns:    1,
+		HeaderRowCount: 2,
+		TimeFunc:       DefaultTime,
+	}
+	testCSV := `col,col,col
+1,2,3
+trash,80,test_name`
+
+	metrics, err := p.Parse([]byte(testCSV))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"col2": int64(80),
+		"col3": "test_name",
+	}, metrics[0].Fields())
+}
+
+func TestMultiHeader(t *testing.T) {
+	p := Parser{
+		HeaderRowCount: 2,
+		TimeFunc:       DefaultTime,
+	}
+	testCSV := `col,col
+1,2
+80,test_name`
+
+	metrics, err := p.Parse([]byte(testCSV))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"col1": int64(80),
+		"col2": "test_name",
+	}, metrics[0].Fields())
+}
+
+func TestParseLineRequiresColumnNames(t *testing.T) {
+	p := Parser{
+		HeaderRowCount: 1,
+		TimeFunc:       DefaultTime,
+	}
+
+	_, err := p.ParseLine("1,2,3")
+	require.Error(t, err)
+}
+
+func TestParseStream(t *testing.T) {
+	p := Parser{
+		MetricName:     "csv",
+		HeaderRowCount: 1,
+		TimeFunc:       DefaultTime,
+	}
+
+	csvHeader := "a,b,c"
+	csvBody := "1,2,3"
+
+	metrics, err := p.Parse([]byte(csvHeader))
+	require.NoError(t, err)
+	require.Len(t, metrics, 0)
+
+	p.ColumnNames = []string{"a", "b", "c"}
+	m, err := p.ParseLine(csvBody)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"a": int64(1),
+		"b": int64(2),
+		"c": int64(3),
+	}, m.Fields())
+}
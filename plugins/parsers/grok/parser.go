@@ -2,6 +2,7 @@ package grok
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"log"
 	"os"
@@ -72,6 +73,7 @@ type Parser struct {
 	CustomPatterns     string
 	CustomPatternFiles []string
 	Measurement        string
+	DefaultTags        map[string]string
 
 	// Timezone is an optional component to help render log dates to
 	// your chosen zone.
@@ -208,7 +210,10 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 
 	fields := make(map[string]interface{})
 	tags := make(map[string]string)
-	timestamp := time.Now()
+	for k, v := range p.DefaultTags {
+		tags[k] = v
+	}
+	timestamp := p.timeFunc()
 	for k, v := range values {
 		if k == "" || v == "" {
 			continue
@@ -345,12 +350,45 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 	}
 
 	if len(fields) == 0 {
-		return nil, fmt.Errorf("logparser_grok: must have one or more fields")
+		return nil, fmt.Errorf("grok: must have one or more fields")
 	}
 
 	return metric.New(p.Measurement, tags, fields, p.tsModder.tsMod(timestamp))
 }
 
+// Parse parses each line of buf, skipping lines that match none of the
+// patterns.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	metrics := make([]telegraf.Metric, 0)
+
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		m, err := p.ParseLine(line)
+		if err != nil {
+			return nil, err
+		}
+		if m == nil {
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
+
+// SetDefaultTags adds tags to the metrics outputs of Parse and ParseLine.
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
 func (p *Parser) addCustomPatterns(scanner *bufio.Scanner) {
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -413,7 +451,7 @@ func (p *Parser) parseTypedCaptures(name, pattern string) (string, error) {
 		// regex capture 2 is the modifier of the capture
 		if strings.HasPrefix(match[2], "ts") {
 			if hasTimestamp {
-				return pattern, fmt.Errorf("logparser pattern compile error: "+
+				return pattern, fmt.Errorf("grok pattern compile error: "+
 					"Each pattern is allowed only one named "+
 					"timestamp data type. pattern: %s", pattern)
 			}
@@ -974,7 +974,7 @@ func TestNewlineInPatterns(t *testing.T) {
 func TestSyslogTimestampParser(t *testing.T) {
 	p := &Parser{
 		Patterns: []string{`%{SYSLOGTIMESTAMP:timestamp:ts-syslog} value=%{NUMBER:value:int}`},
-		timeFunc: func() time.Time { return time.Date(2018, time.April, 1, 0, 0, 0, 0, nil) },
+		timeFunc: func() time.Time { return time.Date(2018, time.April, 1, 0, 0, 0, 0, time.UTC) },
 	}
 	require.NoError(t, p.Compile())
 	m, err := p.ParseLine("Sep 25 09:01:55 value=42")
@@ -982,3 +982,33 @@ func TestSyslogTimestampParser(t *testing.T) {
 	require.NotNil(t, m)
 	require.Equal(t, 2018, m.Time().Year())
 }
+
+func TestParseMultipleLines(t *testing.T) {
+	p := &Parser{
+		Measurement: "grok",
+		Patterns:    []string{"%{NUMBER:num:int} %{WORD:client:tag}"},
+		DefaultTags: map[string]string{"host": "server01"},
+	}
+	require.NoError(t, p.Compile())
+
+	metrics, err := p.Parse([]byte("142 bot\r\nno match\n\n7 human\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+
+	assert.Equal(t, "grok", metrics[0].Name())
+	assert.Equal(t, map[string]interface{}{"num": int64(142)}, metrics[0].Fields())
+	assert.Equal(t, map[string]string{"host": "server01", "client": "bot"}, metrics[0].Tags())
+	assert.Equal(t, map[string]interface{}{"num": int64(7)}, metrics[1].Fields())
+	assert.Equal(t, map[string]string{"host": "server01", "client": "human"}, metrics[1].Tags())
+}
+
+func TestParseEmpty(t *testing.T) {
+	p := &Parser{
+		Patterns: []string{"%{NUMBER:num:int}"},
+	}
+	require.NoError(t, p.Compile())
+
+	metrics, err := p.Parse([]byte(""))
+	require.NoError(t, err)
+	assert.Len(t, metrics, 0)
+}
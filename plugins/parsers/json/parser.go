@@ -6,9 +6,15 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/tidwall/gjson"
+
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/keypath"
 	"github.com/influxdata/telegraf/metric"
 )
 
@@ -20,49 +26,134 @@ type JSONParser struct {
 	MetricName  string
 	TagKeys     []string
 	DefaultTags map[string]string
+
+	// StringFields are the names, or glob patterns, of flattened string
+	// values to keep as fields.
+	StringFields []string
+	// Query is a gjson path selecting the object or array of objects to
+	// parse, the whole document is parsed if empty.
+	Query string
+	// NameKey is the path of the value to use as measurement name.
+	NameKey string
+	// TimeKey is the path of the value to use as timestamp, parsed with
+	// TimeFormat.
+	TimeKey    string
+	TimeFormat string
+
+	stringFilter filter.Filter
+	initOnce     sync.Once
+	initErr      error
 }
 
-func (p *JSONParser) parseArray(buf []byte) ([]telegraf.Metric, error) {
-	metrics := make([]telegraf.Metric, 0)
+func (p *JSONParser) init() error {
+	p.initOnce.Do(func() {
+		if p.TimeKey != "" && p.TimeFormat == "" {
+			p.initErr = fmt.Errorf("use of json_time_key requires json_time_format")
+			return
+		}
 
-	var jsonOut []map[string]interface{}
-	err := json.Unmarshal(buf, &jsonOut)
-	if err != nil {
-		err = fmt.Errorf("unable to parse out as JSON Array, %s", err)
-		return nil, err
-	}
+		var err error
+		p.stringFilter, err = filter.Compile(p.StringFields)
+		if err != nil {
+			p.initErr = fmt.Errorf("compiling json_string_fields: %v", err)
+		}
+	})
+	return p.initErr
+}
+
+// parseArray parses each object of the array into a metric, nested arrays
+// are parsed the same way.
+func (p *JSONParser) parseArray(metrics []telegraf.Metric, jsonOut []interface{}, now time.Time) ([]telegraf.Metric, error) {
+	var err error
 	for _, item := range jsonOut {
-		metrics, err = p.parseObject(metrics, item)
+		switch v := item.(type) {
+		case map[string]interface{}:
+			metrics, err = p.parseObject(metrics, v, now)
+		case []interface{}:
+			metrics, err = p.parseArray(metrics, v, now)
+		default:
+			err = fmt.Errorf("unable to parse out as JSON Array, element is %T instead of an object", item)
+		}
+		if err != nil {
+			return nil, err
+		}
 	}
 	return metrics, nil
 }
 
-func (p *JSONParser) parseObject(metrics []telegraf.Metric, jsonOut map[string]interface{}) ([]telegraf.Metric, error) {
+func (p *JSONParser) parseObject(metrics []telegraf.Metric, jsonOut map[string]interface{}, now time.Time) ([]telegraf.Metric, error) {
 
 	tags := make(map[string]string)
 	for k, v := range p.DefaultTags {
 		tags[k] = v
 	}
 
+	name := p.MetricName
+	if p.NameKey != "" {
+		if v, ok := keypath.Lookup(jsonOut, p.NameKey).(string); ok && v != "" {
+			name = v
+		}
+	}
+
+	timestamp := now
+	if p.TimeKey != "" {
+		v := keypath.Lookup(jsonOut, p.TimeKey)
+		if v == nil {
+			return nil, fmt.Errorf("JSON time key %q could not be found", p.TimeKey)
+		}
+		// format numbers to keep integer timestamps exact
+		if f, ok := v.(float64); ok {
+			v = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		var err error
+		timestamp, err = internal.ParseTimestamp(v, p.TimeFormat)
+		if err != nil {
+			return nil, fmt.Errorf("parsing JSON time key %q: %v", p.TimeKey, err)
+		}
+		keypath.Remove(jsonOut, p.TimeKey)
+	}
+
 	for _, tag := range p.TagKeys {
-		switch v := jsonOut[tag].(type) {
+		// a top-level key matching the tag exactly keeps its name, so keys
+		// containing dots still work as before
+		v, ok := jsonOut[tag]
+		name := tag
+		if ok {
+			delete(jsonOut, tag)
+		} else {
+			v = keypath.Lookup(jsonOut, tag)
+			keypath.Remove(jsonOut, tag)
+			name = keypath.Name(tag)
+		}
+
+		switch v := v.(type) {
 		case string:
-			tags[tag] = v
+			tags[name] = v
 		case bool:
-			tags[tag] = strconv.FormatBool(v)
+			tags[name] = strconv.FormatBool(v)
 		case float64:
-			tags[tag] = strconv.FormatFloat(v, 'f', -1, 64)
+			tags[name] = strconv.FormatFloat(v, 'f', -1, 64)
 		}
-		delete(jsonOut, tag)
 	}
 
 	f := JSONFlattener{}
-	err := f.FlattenJSON("", jsonOut)
+	var err error
+	if len(p.StringFields) > 0 {
+		err = f.FullFlattenJSON("", jsonOut, true, false)
+	} else {
+		err = f.FlattenJSON("", jsonOut)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	metric, err := metric.New(p.MetricName, tags, f.Fields, time.Now().UTC())
+	for k, v := range f.Fields {
+		if _, ok := v.(string); ok && (p.stringFilter == nil || !p.stringFilter.Match(k)) {
+			delete(f.Fields, k)
+		}
+	}
+
+	metric, err := metric.New(name, tags, f.Fields, timestamp)
 
 	if err != nil {
 		return nil, err
@@ -71,23 +162,47 @@ func (p *JSONParser) parseObject(metrics []telegraf.Metric, jsonOut map[string]i
 }
 
 func (p *JSONParser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+
 	buf = bytes.TrimSpace(buf)
 	buf = bytes.TrimPrefix(buf, utf8BOM)
 	if len(buf) == 0 {
 		return make([]telegraf.Metric, 0), nil
 	}
 
+	if p.Query != "" {
+		result := gjson.GetBytes(buf, p.Query)
+		if !result.Exists() {
+			return nil, fmt.Errorf("JSON query %q did not match", p.Query)
+		}
+		buf = bytes.TrimSpace([]byte(result.Raw))
+		if !isarray(buf) && !bytes.HasPrefix(buf, []byte("{")) {
+			return nil, fmt.Errorf("JSON query %q must lead to an object or an array of objects, got: %s",
+				p.Query, result.Raw)
+		}
+	}
+
+	now := time.Now().UTC()
+	metrics := make([]telegraf.Metric, 0)
 	if !isarray(buf) {
-		metrics := make([]telegraf.Metric, 0)
 		var jsonOut map[string]interface{}
 		err := json.Unmarshal(buf, &jsonOut)
 		if err != nil {
 			err = fmt.Errorf("unable to parse out as JSON, %s", err)
 			return nil, err
 		}
-		return p.parseObject(metrics, jsonOut)
+		return p.parseObject(metrics, jsonOut, now)
+	}
+
+	var jsonOut []interface{}
+	err := json.Unmarshal(buf, &jsonOut)
+	if err != nil {
+		err = fmt.Errorf("unable to parse out as JSON Array, %s", err)
+		return nil, err
 	}
-	return p.parseArray(buf)
+	return p.parseArray(metrics, jsonOut, now)
 }
 
 func (p *JSONParser) ParseLine(line string) (telegraf.Metric, error) {
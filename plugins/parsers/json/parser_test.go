@@ -2,8 +2,10 @@ package json
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -440,3 +442,173 @@ func TestHttpJsonBOM(t *testing.T) {
 	_, err := parser.Parse(jsonBOM)
 	assert.NoError(t, err)
 }
+
+const validJSONNested = `
+{
+    "source": "api",
+    "data": {
+        "hosts": [
+            {
+                "name": "server01",
+                "status": "up",
+                "version": "1.2",
+                "meta": {"ts": "2018-07-24T19:43:40Z", "kind": "cpu"},
+                "load": 0.5
+            },
+            {
+                "name": "server02",
+                "status": "down",
+                "version": "1.3",
+                "meta": {"ts": "2018-07-24T19:43:41Z", "kind": "mem"},
+                "load": 1.5
+            }
+        ]
+    }
+}
+`
+
+func TestJSONStringFields(t *testing.T) {
+	parser := JSONParser{
+		MetricName:   "json_test",
+		StringFields: []string{"status", "meta_*"},
+	}
+
+	metrics, err := parser.Parse([]byte(`{"a": 5, "status": "up", "meta": {"kind": "cpu"}, "ignored": "x", "flag": true}`))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, map[string]interface{}{
+		"a":         float64(5),
+		"status":    "up",
+		"meta_kind": "cpu",
+	}, metrics[0].Fields())
+}
+
+func TestJSONQueryExplodesArray(t *testing.T) {
+	parser := JSONParser{
+		MetricName:   "json_test",
+		Query:        "data.hosts",
+		TagKeys:      []string{"name"},
+		StringFields: []string{"status"},
+		NameKey:      "meta.kind",
+		TimeKey:      "meta.ts",
+		TimeFormat:   time.RFC3339,
+	}
+
+	metrics, err := parser.Parse([]byte(validJSONNested))
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+
+	assert.Equal(t, "cpu", metrics[0].Name())
+	assert.Equal(t, map[string]string{"name": "server01"}, metrics[0].Tags())
+	assert.Equal(t, map[string]interface{}{
+		"status": "up",
+		"load":   0.5,
+	}, metrics[0].Fields())
+	assert.Equal(t, time.Date(2018, 7, 24, 19, 43, 40, 0, time.UTC), metrics[0].Time())
+
+	assert.Equal(t, "mem", metrics[1].Name())
+	assert.Equal(t, map[string]string{"name": "server02"}, metrics[1].Tags())
+	assert.Equal(t, time.Date(2018, 7, 24, 19, 43, 41, 0, time.UTC), metrics[1].Time())
+}
+
+func TestNestedTagKeys(t *testing.T) {
+	parser := JSONParser{
+		MetricName: "json_test",
+		Query:      "data.hosts.0",
+		TagKeys:    []string{"name", "meta.kind"},
+	}
+
+	metrics, err := parser.Parse([]byte(validJSONNested))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, map[string]string{
+		"name":      "server01",
+		"meta_kind": "cpu",
+	}, metrics[0].Tags())
+	assert.Equal(t, map[string]interface{}{"load": 0.5}, metrics[0].Fields())
+}
+
+func TestLiteralDottedTagKeys(t *testing.T) {
+	parser := JSONParser{
+		MetricName: "json_test",
+		TagKeys:    []string{"meta.kind", "host.name"},
+	}
+
+	metrics, err := parser.Parse([]byte(`{"meta.kind": "cpu", "host": {"name": "server01"}, "load": 0.5}`))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, map[string]string{
+		"meta.kind": "cpu",
+		"host_name": "server01",
+	}, metrics[0].Tags())
+	assert.Equal(t, map[string]interface{}{"load": 0.5}, metrics[0].Fields())
+}
+
+func TestJSONQueryObject(t *testing.T) {
+	parser := JSONParser{
+		MetricName: "json_test",
+		Query:      "data.hosts.1",
+	}
+
+	metrics, err := parser.Parse([]byte(validJSONNested))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, map[string]interface{}{"load": 1.5}, metrics[0].Fields())
+}
+
+func TestJSONQueryErrors(t *testing.T) {
+	parser := JSONParser{
+		MetricName: "json_test",
+		Query:      "data.missing",
+	}
+	_, err := parser.Parse([]byte(validJSONNested))
+	assert.Error(t, err)
+
+	parser = JSONParser{
+		MetricName: "json_test",
+		Query:      "source",
+	}
+	_, err = parser.Parse([]byte(validJSONNested))
+	assert.Error(t, err)
+}
+
+func TestJSONNestedArrays(t *testing.T) {
+	parser := JSONParser{
+		MetricName: "json_test",
+	}
+
+	metrics, err := parser.Parse([]byte(`[[{"a": 1}, {"a": 2}], [{"a": 3}]]`))
+	require.NoError(t, err)
+	require.Len(t, metrics, 3)
+	assert.Equal(t, map[string]interface{}{"a": float64(3)}, metrics[2].Fields())
+
+	_, err = parser.Parse([]byte(`[1, 2]`))
+	assert.Error(t, err)
+}
+
+func TestJSONTimeKeyUnix(t *testing.T) {
+	parser := JSONParser{
+		MetricName: "json_test",
+		TimeKey:    "time",
+		TimeFormat: "unix_ms",
+	}
+
+	metrics, err := parser.Parse([]byte(`{"time": 1532461420123, "value": 42}`))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, int64(1532461420123000000), metrics[0].Time().UnixNano())
+	assert.Equal(t, map[string]interface{}{"value": float64(42)}, metrics[0].Fields())
+
+	_, err = parser.Parse([]byte(`{"value": 42}`))
+	assert.Error(t, err)
+}
+
+func TestJSONTimeKeyRequiresFormat(t *testing.T) {
+	parser := JSONParser{
+		MetricName: "json_test",
+		TimeKey:    "time",
+	}
+
+	_, err := parser.Parse([]byte(`{"time": 1532461420, "value": 42}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,143 @@
+package logfmt
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logfmt/logfmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+)
+
+var (
+	ErrNoMetric = fmt.Errorf("no metric in line")
+)
+
+// Parser decodes logfmt formatted messages into metrics.
+type Parser struct {
+	MetricName      string
+	TagKeys         []string
+	TimestampKey    string
+	TimestampFormat string
+	DefaultTags     map[string]string
+	Now             func() time.Time
+}
+
+// NewParser creates a parser.
+func NewParser(metricName string, defaultTags map[string]string) *Parser {
+	return &Parser{
+		MetricName:      metricName,
+		DefaultTags:     defaultTags,
+		TimestampFormat: time.RFC3339Nano,
+		Now:             time.Now,
+	}
+}
+
+// Parse converts a slice of bytes in logfmt format to metrics.
+func (p *Parser) Parse(b []byte) ([]telegraf.Metric, error) {
+	reader := bytes.NewReader(b)
+	decoder := logfmt.NewDecoder(reader)
+	metrics := make([]telegraf.Metric, 0)
+	for decoder.ScanRecord() {
+		m, err := p.parseRecord(decoder)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+	if err := decoder.Err(); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// ParseLine converts a single line of text in logfmt format to a metric.
+func (p *Parser) ParseLine(s string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(s))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, ErrNoMetric
+	}
+	return metrics[0], nil
+}
+
+// SetDefaultTags adds tags to the metrics outputs of Parse and ParseLine.
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+func (p *Parser) parseRecord(decoder *logfmt.Decoder) (telegraf.Metric, error) {
+	tags := make(map[string]string)
+	for k, v := range p.DefaultTags {
+		tags[k] = v
+	}
+	fields := make(map[string]interface{})
+	tm := p.Now()
+
+	for decoder.ScanKeyval() {
+		key := string(decoder.Key())
+		value := string(decoder.Value())
+		if value == "" {
+			continue
+		}
+
+		if p.TimestampKey != "" && key == p.TimestampKey {
+			t, err := internal.ParseTimestamp(value, p.TimestampFormat)
+			if err != nil {
+				return nil, fmt.Errorf("parsing timestamp key %q: %v", key, err)
+			}
+			tm = t
+			continue
+		}
+
+		if p.isTag(key) {
+			tags[key] = value
+			continue
+		}
+
+		fields[key] = convert(value)
+	}
+
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return metric.New(p.MetricName, tags, fields, tm)
+}
+
+func (p *Parser) isTag(key string) bool {
+	for _, k := range p.TagKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// convert returns value as an integer, float or boolean if possible.
+func convert(value string) interface{} {
+	if iValue, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return iValue
+	}
+	if fValue, err := strconv.ParseFloat(value, 64); err == nil &&
+		!math.IsNaN(fValue) && !math.IsInf(fValue, 0) {
+		return fValue
+	}
+	switch strings.ToLower(value) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return value
+}
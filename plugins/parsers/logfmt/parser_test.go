@@ -0,0 +1,190 @@
+package logfmt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func DefaultTime() time.Time {
+	return time.Unix(42, 0)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		bytes    []byte
+		tags     map[string]string
+		fields   []map[string]interface{}
+		wantErr  bool
+		tagKeys  []string
+		tsKey    string
+		expected time.Time
+	}{
+		{
+			name:   "no bytes returns no metrics",
+			bytes:  []byte(""),
+			fields: []map[string]interface{}{},
+		},
+		{
+			name:  "test without trailing end",
+			bytes: []byte("foo=\"bar\""),
+			fields: []map[string]interface{}{
+				{"foo": "bar"},
+			},
+		},
+		{
+			name:  "test with trailing end",
+			bytes: []byte("foo=\"bar\"\n"),
+			fields: []map[string]interface{}{
+				{"foo": "bar"},
+			},
+		},
+		{
+			name:  "logfmt parser returns all the fields",
+			bytes: []byte(`ts=2018-07-24T19:43:40.275Z lvl=info msg="http request" method=POST`),
+			fields: []map[string]interface{}{
+				{
+					"lvl":    "info",
+					"msg":    "http request",
+					"method": "POST",
+					"ts":     "2018-07-24T19:43:40.275Z",
+				},
+			},
+		},
+		{
+			name:  "logfmt parser parses every line",
+			bytes: []byte("ts=2018-07-24T19:43:40.275Z lvl=info msg=\"http request\" method=POST\nparent_id=088876RL000 duration=7.45 log_id=09R4e4Rl000"),
+			fields: []map[string]interface{}{
+				{
+					"lvl":    "info",
+					"msg":    "http request",
+					"method": "POST",
+					"ts":     "2018-07-24T19:43:40.275Z",
+				},
+				{
+					"parent_id": "088876RL000",
+					"duration":  7.45,
+					"log_id":    "09R4e4Rl000",
+				},
+			},
+		},
+		{
+			name:    "keys without = or values are ignored",
+			bytes:   []byte(`i am no data.`),
+			fields:  []map[string]interface{}{},
+			wantErr: false,
+		},
+		{
+			name:  "keys without values are ignored",
+			bytes: []byte(`foo="" bar= baz=1`),
+			fields: []map[string]interface{}{
+				{"baz": int64(1)},
+			},
+		},
+		{
+			name:    "unterminated quote produces error",
+			bytes:   []byte(`bar=baz foo="bar`),
+			wantErr: true,
+		},
+		{
+			name:  "values are converted",
+			bytes: []byte(`int=42 float=4.2 neg=-1 t=true f=FALSE nan=NaN s=hello`),
+			fields: []map[string]interface{}{
+				{
+					"int":   int64(42),
+					"float": 4.2,
+					"neg":   int64(-1),
+					"t":     true,
+					"f":     false,
+					"nan":   "NaN",
+					"s":     "hello",
+				},
+			},
+		},
+		{
+			name:    "tag keys",
+			bytes:   []byte(`lvl=info service=api duration=12`),
+			tagKeys: []string{"lvl", "service"},
+			tags:    map[string]string{"lvl": "info", "service": "api"},
+			fields: []map[string]interface{}{
+				{"duration": int64(12)},
+			},
+		},
+		{
+			name:     "timestamp key",
+			bytes:    []byte(`ts=2018-07-24T19:43:40.275Z duration=12`),
+			tsKey:    "ts",
+			expected: time.Date(2018, 7, 24, 19, 43, 40, 275000000, time.UTC),
+			fields: []map[string]interface{}{
+				{"duration": int64(12)},
+			},
+		},
+		{
+			name:    "invalid timestamp",
+			bytes:   []byte(`ts=yesterday duration=12`),
+			tsKey:   "ts",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewParser("testlog", nil)
+			l.Now = DefaultTime
+			l.TagKeys = tt.tagKeys
+			l.TimestampKey = tt.tsKey
+
+			got, err := l.Parse(tt.bytes)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, got, len(tt.fields))
+
+			expectedTime := DefaultTime()
+			if !tt.expected.IsZero() {
+				expectedTime = tt.expected
+			}
+			expectedTags := tt.tags
+			if expectedTags == nil {
+				expectedTags = map[string]string{}
+			}
+
+			for i, m := range got {
+				assert.Equal(t, "testlog", m.Name())
+				assert.Equal(t, tt.fields[i], m.Fields())
+				assert.Equal(t, expectedTags, m.Tags())
+				assert.Equal(t, expectedTime.UnixNano(), m.Time().UnixNano())
+			}
+		})
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	l := NewParser("testlog", map[string]string{"host": "server01"})
+	l.Now = DefaultTime
+
+	m, err := l.ParseLine(`lvl=info duration=1.5`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "server01"}, m.Tags())
+	assert.Equal(t, map[string]interface{}{
+		"lvl":      "info",
+		"duration": 1.5,
+	}, m.Fields())
+
+	_, err = l.ParseLine(`no data here`)
+	assert.Equal(t, ErrNoMetric, err)
+}
+
+func TestTimestampFormat(t *testing.T) {
+	l := NewParser("testlog", nil)
+	l.TimestampKey = "time"
+	l.TimestampFormat = "unix"
+
+	m, err := l.ParseLine(`time=1532461420 value=1`)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1532461420), m.Time().Unix())
+}
@@ -0,0 +1,256 @@
+package msgpack
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// timestampExt is the MessagePack extension type of timestamps.
+	timestampExt = -1
+
+	// maxDepth is the maximum nesting of arrays and maps.
+	maxDepth = 64
+)
+
+var (
+	errShortBuffer = errors.New("unexpected end of data")
+	errMaxDepth    = errors.New("maximum nesting depth exceeded")
+)
+
+// decoder reads MessagePack values from a buffer.
+type decoder struct {
+	buf   []byte
+	pos   int
+	depth int
+}
+
+func (d *decoder) more() bool {
+	return d.pos < len(d.buf)
+}
+
+func (d *decoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.buf)-d.pos < n {
+		return nil, errShortBuffer
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+// container checks the header of an array or map of n elements of at least
+// size bytes each, the elements must fit in the rest of the buffer.
+func (d *decoder) container(n int, size int) error {
+	if n < 0 || n > (len(d.buf)-d.pos)/size {
+		return errShortBuffer
+	}
+	if d.depth >= maxDepth {
+		return errMaxDepth
+	}
+	return nil
+}
+
+func (d *decoder) uint(n int) (uint64, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return 0, err
+	}
+	switch n {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+// decode returns the next value.  Integers are returned as int64, or uint64
+// if they overflow an int64, floats as float64, maps as
+// map[string]interface{} and timestamps as time.Time.
+func (d *decoder) decode() (interface{}, error) {
+	b, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.decodeMap(int(c & 0x0f))
+	case c&0xf0 == 0x90:
+		return d.decodeArray(int(c & 0x0f))
+	case c&0xe0 == 0xa0:
+		return d.decodeString(int(c & 0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err := d.uint(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if v > math.MaxInt64 {
+			return v, nil
+		}
+		return int64(v), nil
+	case 0xd0:
+		v, err := d.uint(1)
+		return int64(int8(v)), err
+	case 0xd1:
+		v, err := d.uint(2)
+		return int64(int16(v)), err
+	case 0xd2:
+		v, err := d.uint(4)
+		return int64(int32(v)), err
+	case 0xd3:
+		v, err := d.uint(8)
+		return int64(v), err
+	case 0xca:
+		v, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(v))), err
+	case 0xcb:
+		v, err := d.uint(8)
+		return math.Float64frombits(v), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(int(n))
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		return d.next(int(n))
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(int(n))
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(int(n))
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.decodeExt(1 << (c - 0xd4))
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.uint(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeExt(int(n))
+	default:
+		return nil, fmt.Errorf("invalid MessagePack type 0x%x", c)
+	}
+}
+
+func (d *decoder) decodeString(n int) (interface{}, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *decoder) decodeArray(n int) (interface{}, error) {
+	if err := d.container(n, 1); err != nil {
+		return nil, err
+	}
+	d.depth++
+	defer func() { d.depth-- }()
+
+	array := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		v, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, v)
+	}
+	return array, nil
+}
+
+func (d *decoder) decodeMap(n int) (interface{}, error) {
+	if err := d.container(n, 2); err != nil {
+		return nil, err
+	}
+	d.depth++
+	defer func() { d.depth-- }()
+
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		k, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("unsupported map key type %T", k)
+		}
+		v, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+// decodeExt decodes an extension with n bytes of data, only the timestamp
+// extension is supported.
+func (d *decoder) decodeExt(n int) (interface{}, error) {
+	t, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	if int8(t[0]) != timestampExt {
+		return nil, fmt.Errorf("unsupported MessagePack extension type %d", int8(t[0]))
+	}
+
+	switch n {
+	case 4:
+		sec, err := d.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return time.Unix(int64(sec), 0), nil
+	case 8:
+		v, err := d.uint(8)
+		if err != nil {
+			return nil, err
+		}
+		return time.Unix(int64(v&0x3ffffffff), int64(v>>34)), nil
+	case 12:
+		nsec, err := d.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		sec, err := d.uint(8)
+		if err != nil {
+			return nil, err
+		}
+		return time.Unix(int64(sec), int64(nsec)), nil
+	default:
+		return nil, fmt.Errorf("invalid timestamp length %d", n)
+	}
+}
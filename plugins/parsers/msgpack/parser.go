@@ -0,0 +1,161 @@
+package msgpack
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+var (
+	ErrNoMetric = fmt.Errorf("no metric in data")
+)
+
+// Parser decodes MessagePack encoded metrics, using the layout written by the
+// msgpack serializer.  The data may contain one or more concatenated metric
+// maps, or a map with a "metrics" array as written by SerializeBatch.
+type Parser struct {
+	MetricName  string
+	DefaultTags map[string]string
+	Now         func() time.Time
+}
+
+// NewParser creates a parser.
+func NewParser(metricName string, defaultTags map[string]string) *Parser {
+	return &Parser{
+		MetricName:  metricName,
+		DefaultTags: defaultTags,
+		Now:         time.Now,
+	}
+}
+
+// Parse converts a slice of bytes in MessagePack format to metrics.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	now := p.Now()
+	d := &decoder{buf: buf}
+
+	metrics := make([]telegraf.Metric, 0)
+	for d.more() {
+		v, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected a map, got %T", v)
+		}
+
+		if batch, ok := obj["metrics"]; ok {
+			array, ok := batch.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("metrics must be an array, got %T", batch)
+			}
+			for _, item := range array {
+				obj, ok := item.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("expected a map, got %T", item)
+				}
+				m, err := p.parseObject(obj, now)
+				if err != nil {
+					return nil, err
+				}
+				metrics = append(metrics, m)
+			}
+			continue
+		}
+
+		m, err := p.parseObject(obj, now)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
+
+// ParseLine converts a single MessagePack encoded metric to a metric.
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, ErrNoMetric
+	}
+	return metrics[0], nil
+}
+
+// SetDefaultTags adds tags to the metrics outputs of Parse and ParseLine.
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+func (p *Parser) parseObject(obj map[string]interface{}, now time.Time) (telegraf.Metric, error) {
+	name := p.MetricName
+	if v, ok := obj["name"]; ok {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("name must be a string, got %T", v)
+		}
+		name = s
+	}
+
+	tags := make(map[string]string)
+	for k, v := range p.DefaultTags {
+		tags[k] = v
+	}
+	if v, ok := obj["tags"]; ok {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("tags must be a map, got %T", v)
+		}
+		for k, v := range m {
+			switch v := v.(type) {
+			case string:
+				tags[k] = v
+			case []byte:
+				tags[k] = string(v)
+			default:
+				tags[k] = fmt.Sprint(v)
+			}
+		}
+	}
+
+	fields := make(map[string]interface{})
+	if v, ok := obj["fields"]; ok {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("fields must be a map, got %T", v)
+		}
+		for k, v := range m {
+			switch v := v.(type) {
+			case int64, uint64, float64, string, bool:
+				fields[k] = v
+			case []byte:
+				fields[k] = string(v)
+			}
+		}
+	}
+
+	tm := now
+	if v, ok := obj["timestamp"]; ok {
+		switch v := v.(type) {
+		case time.Time:
+			tm = v
+		case int64:
+			tm = time.Unix(v, 0)
+		case uint64:
+			tm = time.Unix(int64(v), 0)
+		case float64:
+			sec := int64(v)
+			tm = time.Unix(sec, int64((v-float64(sec))*1e9))
+		default:
+			return nil, fmt.Errorf("invalid timestamp type %T", v)
+		}
+	}
+
+	return metric.New(name, tags, fields, tm)
+}
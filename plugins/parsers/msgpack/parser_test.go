@@ -0,0 +1,168 @@
+package msgpack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func DefaultTime() time.Time {
+	return time.Unix(42, 0)
+}
+
+// {"name": "cpu", "tags": {"host": "a"}, "fields": {"value": 42}}
+var cpuMetric = []byte{
+	0x83,
+	0xa4, 'n', 'a', 'm', 'e', 0xa3, 'c', 'p', 'u',
+	0xa4, 't', 'a', 'g', 's', 0x81, 0xa4, 'h', 'o', 's', 't', 0xa1, 'a',
+	0xa6, 'f', 'i', 'e', 'l', 'd', 's', 0x81, 0xa5, 'v', 'a', 'l', 'u', 'e', 0x2a,
+}
+
+func TestParse(t *testing.T) {
+	p := NewParser("msgpack", nil)
+	p.Now = DefaultTime
+
+	metrics, err := p.Parse(cpuMetric)
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "cpu", metrics[0].Name())
+	assert.Equal(t, map[string]string{"host": "a"}, metrics[0].Tags())
+	assert.Equal(t, map[string]interface{}{"value": int64(42)}, metrics[0].Fields())
+	assert.Equal(t, DefaultTime(), metrics[0].Time())
+}
+
+func TestParseConcatenated(t *testing.T) {
+	p := NewParser("msgpack", nil)
+	p.Now = DefaultTime
+
+	buf := append(append([]byte{}, cpuMetric...), cpuMetric...)
+	metrics, err := p.Parse(buf)
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+}
+
+func TestParseBatch(t *testing.T) {
+	p := NewParser("msgpack", nil)
+	p.Now = DefaultTime
+
+	buf := []byte{0x81, 0xa7, 'm', 'e', 't', 'r', 'i', 'c', 's', 0x92}
+	buf = append(buf, cpuMetric...)
+	buf = append(buf, cpuMetric...)
+	metrics, err := p.Parse(buf)
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	assert.Equal(t, "cpu", metrics[1].Name())
+}
+
+func TestParseValueTypes(t *testing.T) {
+	p := NewParser("msgpack", nil)
+	p.Now = DefaultTime
+
+	// {"fields": {"f": 1.5, "i": -200, "u": uint64 max, "b": true, "s": "x", "n": nil}}
+	buf := []byte{
+		0x81,
+		0xa6, 'f', 'i', 'e', 'l', 'd', 's', 0x86,
+		0xa1, 'f', 0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0,
+		0xa1, 'i', 0xd1, 0xff, 0x38,
+		0xa1, 'u', 0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xa1, 'b', 0xc3,
+		0xa1, 's', 0xa1, 'x',
+		0xa1, 'n', 0xc0,
+	}
+	m, err := p.ParseLine(string(buf))
+	require.NoError(t, err)
+	assert.Equal(t, "msgpack", m.Name())
+	assert.Equal(t, map[string]interface{}{
+		"f": 1.5,
+		"i": int64(-200),
+		"u": uint64(18446744073709551615),
+		"b": true,
+		"s": "x",
+	}, m.Fields())
+}
+
+func TestParseTimestamp(t *testing.T) {
+	p := NewParser("msgpack", map[string]string{"region": "us"})
+
+	tests := []struct {
+		name     string
+		value    []byte
+		expected time.Time
+	}{
+		{
+			name:     "timestamp 32",
+			value:    []byte{0xd6, 0xff, 0x5b, 0x57, 0x7b, 0x80},
+			expected: time.Unix(1532459904, 0),
+		},
+		{
+			name:     "timestamp 64",
+			value:    []byte{0xd7, 0xff, 0x00, 0x00, 0x00, 0x04, 0x5b, 0x57, 0x7b, 0x80},
+			expected: time.Unix(1532459904, 1),
+		},
+		{
+			name: "timestamp 96",
+			value: []byte{0xc7, 12, 0xff, 0x00, 0x00, 0x00, 0x01,
+				0x00, 0x00, 0x00, 0x00, 0x5b, 0x57, 0x7b, 0x80},
+			expected: time.Unix(1532459904, 1),
+		},
+		{
+			name:     "unix seconds",
+			value:    []byte{0xce, 0x5b, 0x57, 0x7b, 0x80},
+			expected: time.Unix(1532459904, 0),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := []byte{0x82, 0xa1, 'x', 0xa1, 'y',
+				0xa9, 't', 'i', 'm', 'e', 's', 't', 'a', 'm', 'p'}
+			buf = append(buf, tt.value...)
+			m, err := p.ParseLine(string(buf))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected.UnixNano(), m.Time().UnixNano())
+			assert.Equal(t, map[string]string{"region": "us"}, m.Tags())
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	p := NewParser("msgpack", nil)
+
+	_, err := p.Parse([]byte{0x2a})
+	require.Error(t, err)
+
+	_, err = p.Parse(cpuMetric[:len(cpuMetric)-1])
+	require.Error(t, err)
+
+	_, err = p.ParseLine("")
+	require.Equal(t, ErrNoMetric, err)
+}
+
+func TestParseInvalidHeaders(t *testing.T) {
+	deep := make([]byte, maxDepth+1)
+	for i := range deep {
+		deep[i] = 0x91
+	}
+
+	tests := []struct {
+		name string
+		buf  []byte
+	}{
+		{"oversized array", []byte{0xdd, 0x7f, 0xff, 0xff, 0xff}},
+		{"oversized map", []byte{0xdf, 0x7f, 0xff, 0xff, 0xff}},
+		{"oversized string", []byte{0xdb, 0x7f, 0xff, 0xff, 0xff}},
+		{"truncated array header", []byte{0xdd, 0x00, 0x01}},
+		{"truncated map header", []byte{0xde, 0x00}},
+		{"truncated array", []byte{0x93, 0x01, 0x02}},
+		{"truncated map", []byte{0x82, 0xa1, 'a', 0x01, 0xa1, 'b'}},
+		{"nesting too deep", append(deep, 0x01)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &decoder{buf: tt.buf}
+			_, err := d.decode()
+			require.Error(t, err)
+		})
+	}
+}
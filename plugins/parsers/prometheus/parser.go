@@ -21,6 +21,54 @@ import (
 	"github.com/prometheus/common/expfmt"
 )
 
+// Parser parses the Prometheus text exposition format, or the delimited
+// protocol buffer format if announced by the Content-Type of Header.
+type Parser struct {
+	Header      http.Header
+	DefaultTags map[string]string
+}
+
+// Parse converts buf into metrics, adding the default tags.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	header := p.Header
+	if header == nil {
+		header = http.Header{}
+	}
+
+	metrics, err := Parse(buf, header)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range metrics {
+		for k, v := range p.DefaultTags {
+			if !m.HasTag(k) {
+				m.AddTag(k, v)
+			}
+		}
+	}
+	return metrics, nil
+}
+
+// ParseLine parses a single sample line, which is untyped unless preceded by
+// a TYPE comment.
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line + "\n"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, fmt.Errorf("no metrics in line")
+	}
+	return metrics[0], nil
+}
+
+// SetDefaultTags adds tags to the metrics outputs of Parse and ParseLine.
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
 // Parse returns a slice of Metrics from a text representation of a
 // metrics
 func Parse(buf []byte, header http.Header) ([]telegraf.Metric, error) {
@@ -86,7 +134,7 @@ func Parse(buf []byte, header http.Header) ([]telegraf.Metric, error) {
 				} else {
 					t = time.Now()
 				}
-				metric, err := metric.New(metricName, tags, fields, t, valueType(mf.GetType()))
+				metric, err := metric.New(metricName, tags, fields, t, ValueType(mf.GetType()))
 				if err == nil {
 					metrics = append(metrics, metric)
 				}
@@ -94,10 +142,11 @@ func Parse(buf []byte, header http.Header) ([]telegraf.Metric, error) {
 		}
 	}
 
-	return metrics, err
+	return metrics, nil
 }
 
-func valueType(mt dto.MetricType) telegraf.ValueType {
+// ValueType returns the telegraf value type of a Prometheus metric type.
+func ValueType(mt dto.MetricType) telegraf.ValueType {
 	switch mt {
 	case dto.MetricType_COUNTER:
 		return telegraf.Counter
@@ -112,6 +161,35 @@ func valueType(mt dto.MetricType) telegraf.ValueType {
 	}
 }
 
+// MetricType returns the Prometheus metric type of a telegraf value type.
+func MetricType(vt telegraf.ValueType) dto.MetricType {
+	switch vt {
+	case telegraf.Counter:
+		return dto.MetricType_COUNTER
+	case telegraf.Gauge:
+		return dto.MetricType_GAUGE
+	case telegraf.Summary:
+		return dto.MetricType_SUMMARY
+	case telegraf.Histogram:
+		return dto.MetricType_HISTOGRAM
+	default:
+		return dto.MetricType_UNTYPED
+	}
+}
+
+// ValueFieldName returns the name of the field holding the value of a
+// counter, gauge or untyped sample.
+func ValueFieldName(mt dto.MetricType) string {
+	switch mt {
+	case dto.MetricType_COUNTER:
+		return "counter"
+	case dto.MetricType_GAUGE:
+		return "gauge"
+	default:
+		return "value"
+	}
+}
+
 // Get Quantiles from summary metric
 func makeQuantiles(m *dto.Metric) map[string]interface{} {
 	fields := make(map[string]interface{})
@@ -146,15 +224,15 @@ func getNameAndValue(m *dto.Metric) map[string]interface{} {
 	fields := make(map[string]interface{})
 	if m.Gauge != nil {
 		if !math.IsNaN(m.GetGauge().GetValue()) {
-			fields["gauge"] = float64(m.GetGauge().GetValue())
+			fields[ValueFieldName(dto.MetricType_GAUGE)] = float64(m.GetGauge().GetValue())
 		}
 	} else if m.Counter != nil {
 		if !math.IsNaN(m.GetCounter().GetValue()) {
-			fields["counter"] = float64(m.GetCounter().GetValue())
+			fields[ValueFieldName(dto.MetricType_COUNTER)] = float64(m.GetCounter().GetValue())
 		}
 	} else if m.Untyped != nil {
 		if !math.IsNaN(m.GetUntyped().GetValue()) {
-			fields["value"] = float64(m.GetUntyped().GetValue())
+			fields[ValueFieldName(dto.MetricType_UNTYPED)] = float64(m.GetUntyped().GetValue())
 		}
 	}
 	return fields
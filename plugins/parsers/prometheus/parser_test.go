@@ -5,7 +5,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var exptime = time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
@@ -165,3 +167,33 @@ func TestParseValidPrometheus(t *testing.T) {
 		metrics[0].Tags())
 
 }
+
+func TestParserDefaultTags(t *testing.T) {
+	p := &Parser{}
+	p.SetDefaultTags(map[string]string{"host": "server01", "handler": "default"})
+
+	metrics, err := p.Parse([]byte(validData))
+	require.NoError(t, err)
+	require.Len(t, metrics, 5)
+	for _, m := range metrics {
+		assert.Equal(t, "server01", m.Tags()["host"])
+		if m.Name() == "http_request_duration_microseconds" {
+			assert.Equal(t, "prometheus", m.Tags()["handler"])
+		}
+	}
+}
+
+func TestParserParseLine(t *testing.T) {
+	p := &Parser{}
+
+	m, err := p.ParseLine(`http_requests_total{method="post",code="200"} 1027 1395066363000`)
+	require.NoError(t, err)
+	assert.Equal(t, "http_requests_total", m.Name())
+	assert.Equal(t, telegraf.Untyped, m.Type())
+	assert.Equal(t, map[string]interface{}{"value": float64(1027)}, m.Fields())
+	assert.Equal(t, map[string]string{"method": "post", "code": "200"}, m.Tags())
+	assert.Equal(t, int64(1395066363000), m.Time().UnixNano()/int64(time.Millisecond))
+
+	_, err = p.ParseLine(validUniqueLine)
+	assert.Error(t, err)
+}
@@ -0,0 +1,272 @@
+package protobuf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+const timestampType = ".google.protobuf.Timestamp"
+
+// Protocol Buffers wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("truncated message")
+
+// registry holds the message and enum types of a descriptor set by fully
+// qualified name, including the leading dot used in type references.
+type registry struct {
+	messages map[string]*descriptor.DescriptorProto
+	enums    map[string]*descriptor.EnumDescriptorProto
+}
+
+func newRegistry(set *descriptor.FileDescriptorSet) *registry {
+	r := &registry{
+		messages: make(map[string]*descriptor.DescriptorProto),
+		enums:    make(map[string]*descriptor.EnumDescriptorProto),
+	}
+	for _, file := range set.GetFile() {
+		prefix := ""
+		if file.GetPackage() != "" {
+			prefix = "." + file.GetPackage()
+		}
+		for _, enum := range file.GetEnumType() {
+			r.enums[prefix+"."+enum.GetName()] = enum
+		}
+		for _, message := range file.GetMessageType() {
+			r.addMessage(prefix, message)
+		}
+	}
+	return r
+}
+
+func (r *registry) addMessage(prefix string, message *descriptor.DescriptorProto) {
+	name := prefix + "." + message.GetName()
+	r.messages[name] = message
+	for _, enum := range message.GetEnumType() {
+		r.enums[name+"."+enum.GetName()] = enum
+	}
+	for _, nested := range message.GetNestedType() {
+		r.addMessage(name, nested)
+	}
+}
+
+// rawValue is a field value as read from the wire.
+type rawValue struct {
+	wireType int
+	x        uint64
+	b        []byte
+}
+
+// decode decodes a message into a map keyed by field name.  Nested messages
+// are decoded as maps, map fields as maps keyed by the string form of the
+// key, repeated fields as slices, enums as the name of the value and
+// google.protobuf.Timestamp messages as time.Time.  Unknown fields are
+// skipped.
+func (r *registry) decode(buf []byte, message *descriptor.DescriptorProto) (map[string]interface{}, error) {
+	fields := make(map[int32]*descriptor.FieldDescriptorProto)
+	for _, field := range message.GetField() {
+		fields[field.GetNumber()] = field
+	}
+
+	obj := make(map[string]interface{})
+	for len(buf) > 0 {
+		key, n := proto.DecodeVarint(buf)
+		if n == 0 {
+			return nil, errTruncated
+		}
+		buf = buf[n:]
+
+		raw := rawValue{wireType: int(key & 7)}
+		switch raw.wireType {
+		case wireVarint:
+			raw.x, n = proto.DecodeVarint(buf)
+			if n == 0 {
+				return nil, errTruncated
+			}
+		case wireFixed64:
+			if len(buf) < 8 {
+				return nil, errTruncated
+			}
+			raw.x, n = binary.LittleEndian.Uint64(buf), 8
+		case wireFixed32:
+			if len(buf) < 4 {
+				return nil, errTruncated
+			}
+			raw.x, n = uint64(binary.LittleEndian.Uint32(buf)), 4
+		case wireBytes:
+			length, m := proto.DecodeVarint(buf)
+			if m == 0 || uint64(len(buf)-m) < length {
+				return nil, errTruncated
+			}
+			raw.b, n = buf[m:m+int(length)], m+int(length)
+		default:
+			return nil, fmt.Errorf("unsupported wire type %d", raw.wireType)
+		}
+		buf = buf[n:]
+
+		field, ok := fields[int32(key>>3)]
+		if !ok {
+			continue
+		}
+		if err := r.setField(obj, field, raw); err != nil {
+			return nil, fmt.Errorf("field %q: %v", field.GetName(), err)
+		}
+	}
+	return obj, nil
+}
+
+func (r *registry) setField(obj map[string]interface{}, field *descriptor.FieldDescriptorProto, raw rawValue) error {
+	name := field.GetName()
+
+	// packed repeated scalars
+	if raw.wireType == wireBytes && isPackable(field.GetType()) {
+		values, _ := obj[name].([]interface{})
+		for _, x := range r.unpack(field.GetType(), raw.b) {
+			v, err := r.value(field, rawValue{x: x})
+			if err != nil {
+				return err
+			}
+			values = append(values, v)
+		}
+		obj[name] = values
+		return nil
+	}
+
+	v, err := r.value(field, raw)
+	if err != nil {
+		return err
+	}
+
+	if entry, ok := r.messages[field.GetTypeName()]; ok && entry.GetOptions().GetMapEntry() {
+		m, ok := obj[name].(map[string]interface{})
+		if !ok {
+			m = make(map[string]interface{})
+			obj[name] = m
+		}
+		kv := v.(map[string]interface{})
+		m[fmt.Sprint(kv["key"])] = kv["value"]
+		return nil
+	}
+
+	if field.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED {
+		values, _ := obj[name].([]interface{})
+		obj[name] = append(values, v)
+		return nil
+	}
+	obj[name] = v
+	return nil
+}
+
+func (r *registry) value(field *descriptor.FieldDescriptorProto, raw rawValue) (interface{}, error) {
+	x := raw.x
+	switch field.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_DOUBLE:
+		return math.Float64frombits(x), nil
+	case descriptor.FieldDescriptorProto_TYPE_FLOAT:
+		return float64(math.Float32frombits(uint32(x))), nil
+	case descriptor.FieldDescriptorProto_TYPE_INT64,
+		descriptor.FieldDescriptorProto_TYPE_SFIXED64:
+		return int64(x), nil
+	case descriptor.FieldDescriptorProto_TYPE_INT32,
+		descriptor.FieldDescriptorProto_TYPE_SFIXED32:
+		return int64(int32(x)), nil
+	case descriptor.FieldDescriptorProto_TYPE_UINT32,
+		descriptor.FieldDescriptorProto_TYPE_FIXED32:
+		return int64(uint32(x)), nil
+	case descriptor.FieldDescriptorProto_TYPE_UINT64,
+		descriptor.FieldDescriptorProto_TYPE_FIXED64:
+		return x, nil
+	case descriptor.FieldDescriptorProto_TYPE_SINT32:
+		return int64(int32(uint32(x)>>1) ^ -int32(x&1)), nil
+	case descriptor.FieldDescriptorProto_TYPE_SINT64:
+		return int64(x>>1) ^ -int64(x&1), nil
+	case descriptor.FieldDescriptorProto_TYPE_BOOL:
+		return x != 0, nil
+	case descriptor.FieldDescriptorProto_TYPE_ENUM:
+		if enum, ok := r.enums[field.GetTypeName()]; ok {
+			for _, value := range enum.GetValue() {
+				if value.GetNumber() == int32(x) {
+					return value.GetName(), nil
+				}
+			}
+		}
+		return int64(int32(x)), nil
+	case descriptor.FieldDescriptorProto_TYPE_STRING:
+		return string(raw.b), nil
+	case descriptor.FieldDescriptorProto_TYPE_BYTES:
+		return append([]byte(nil), raw.b...), nil
+	case descriptor.FieldDescriptorProto_TYPE_MESSAGE:
+		message, ok := r.messages[field.GetTypeName()]
+		if !ok {
+			return nil, fmt.Errorf("unknown message type %q", field.GetTypeName())
+		}
+		obj, err := r.decode(raw.b, message)
+		if err != nil {
+			return nil, err
+		}
+		if field.GetTypeName() == timestampType {
+			seconds, _ := obj["seconds"].(int64)
+			nanos, _ := obj["nanos"].(int64)
+			return time.Unix(seconds, nanos), nil
+		}
+		return obj, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", field.GetType())
+	}
+}
+
+// unpack splits the data of a packed repeated field into its values.
+func (r *registry) unpack(t descriptor.FieldDescriptorProto_Type, buf []byte) []uint64 {
+	var values []uint64
+	for len(buf) > 0 {
+		switch t {
+		case descriptor.FieldDescriptorProto_TYPE_DOUBLE,
+			descriptor.FieldDescriptorProto_TYPE_FIXED64,
+			descriptor.FieldDescriptorProto_TYPE_SFIXED64:
+			if len(buf) < 8 {
+				return values
+			}
+			values = append(values, binary.LittleEndian.Uint64(buf))
+			buf = buf[8:]
+		case descriptor.FieldDescriptorProto_TYPE_FLOAT,
+			descriptor.FieldDescriptorProto_TYPE_FIXED32,
+			descriptor.FieldDescriptorProto_TYPE_SFIXED32:
+			if len(buf) < 4 {
+				return values
+			}
+			values = append(values, uint64(binary.LittleEndian.Uint32(buf)))
+			buf = buf[4:]
+		default:
+			x, n := proto.DecodeVarint(buf)
+			if n == 0 {
+				return values
+			}
+			values = append(values, x)
+			buf = buf[n:]
+		}
+	}
+	return values
+}
+
+func isPackable(t descriptor.FieldDescriptorProto_Type) bool {
+	switch t {
+	case descriptor.FieldDescriptorProto_TYPE_STRING,
+		descriptor.FieldDescriptorProto_TYPE_BYTES,
+		descriptor.FieldDescriptorProto_TYPE_MESSAGE,
+		descriptor.FieldDescriptorProto_TYPE_GROUP:
+		return false
+	default:
+		return true
+	}
+}
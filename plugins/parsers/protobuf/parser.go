@@ -0,0 +1,210 @@
+package protobuf
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/keypath"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// Parser decodes Protocol Buffers messages into metrics.  Messages are
+// described by a FileDescriptorSet, as created by
+// `protoc --include_imports --descriptor_set_out`, and each buffer holds a
+// single message of MessageType.
+//
+// Paths select values of the decoded message, with nested fields separated
+// by dots.
+type Parser struct {
+	MetricName string
+	// DescriptorFile is the path of the descriptor set.
+	DescriptorFile string
+	// MessageType is the fully qualified name of the message type.
+	MessageType string
+	// MeasurementPath is the path of the measurement name, MetricName is
+	// used if empty or not set in the message.
+	MeasurementPath string
+	// TagPaths are the paths of the values to add as tags.
+	TagPaths []string
+	// FieldPaths are the paths of the values to add as fields, all other
+	// values are added if empty.
+	FieldPaths []string
+	// TimestampPath is the path of the timestamp, parsed with
+	// TimestampFormat unless it is a google.protobuf.Timestamp.
+	TimestampPath   string
+	TimestampFormat string
+	DefaultTags     map[string]string
+	Now             func() time.Time
+
+	registry *registry
+	message  *descriptor.DescriptorProto
+}
+
+// Init loads the descriptor set and looks up the message type.
+func (p *Parser) Init() error {
+	if p.DescriptorFile == "" {
+		return fmt.Errorf("protobuf: descriptor file is required")
+	}
+	buf, err := ioutil.ReadFile(p.DescriptorFile)
+	if err != nil {
+		return fmt.Errorf("protobuf: reading descriptor: %v", err)
+	}
+	set := &descriptor.FileDescriptorSet{}
+	if err := proto.Unmarshal(buf, set); err != nil {
+		return fmt.Errorf("protobuf: decoding descriptor: %v", err)
+	}
+	return p.setDescriptor(set)
+}
+
+func (p *Parser) setDescriptor(set *descriptor.FileDescriptorSet) error {
+	if p.Now == nil {
+		p.Now = time.Now
+	}
+
+	p.registry = newRegistry(set)
+	message, ok := p.registry.messages["."+strings.TrimPrefix(p.MessageType, ".")]
+	if !ok {
+		return fmt.Errorf("protobuf: message type %q not found in descriptor", p.MessageType)
+	}
+	p.message = message
+	return nil
+}
+
+// Parse converts a single encoded message to a metric.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	if p.message == nil {
+		return nil, fmt.Errorf("protobuf: parser not initialized")
+	}
+
+	obj, err := p.registry.decode(buf, p.message)
+	if err != nil {
+		return nil, fmt.Errorf("protobuf: %v", err)
+	}
+
+	m, err := p.parseObject(obj)
+	if err != nil {
+		return nil, err
+	}
+	return []telegraf.Metric{m}, nil
+}
+
+// ParseLine converts a single encoded message to a metric.
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+	return metrics[0], nil
+}
+
+// SetDefaultTags adds tags to the metrics outputs of Parse and ParseLine.
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+func (p *Parser) parseObject(obj map[string]interface{}) (telegraf.Metric, error) {
+	name := p.MetricName
+	if p.MeasurementPath != "" {
+		if v, ok := keypath.Lookup(obj, p.MeasurementPath).(string); ok && v != "" {
+			name = v
+		}
+		keypath.Remove(obj, p.MeasurementPath)
+	}
+
+	timestamp := p.Now()
+	if p.TimestampPath != "" {
+		v := keypath.Lookup(obj, p.TimestampPath)
+		switch v := v.(type) {
+		case nil:
+			return nil, fmt.Errorf("protobuf: timestamp path %q could not be found", p.TimestampPath)
+		case time.Time:
+			timestamp = v
+		default:
+			var err error
+			timestamp, err = internal.ParseTimestamp(v, p.TimestampFormat)
+			if err != nil {
+				return nil, fmt.Errorf("protobuf: parsing timestamp path %q: %v", p.TimestampPath, err)
+			}
+		}
+		keypath.Remove(obj, p.TimestampPath)
+	}
+
+	tags := make(map[string]string)
+	for k, v := range p.DefaultTags {
+		tags[k] = v
+	}
+	for _, path := range p.TagPaths {
+		if v, ok := tagValue(keypath.Lookup(obj, path)); ok {
+			tags[keypath.Name(path)] = v
+		}
+		keypath.Remove(obj, path)
+	}
+
+	fields := make(map[string]interface{})
+	if len(p.FieldPaths) > 0 {
+		for _, path := range p.FieldPaths {
+			flatten(fields, keypath.Name(path), keypath.Lookup(obj, path))
+		}
+	} else {
+		flatten(fields, "", obj)
+	}
+
+	return metric.New(name, tags, fields, timestamp)
+}
+
+// flatten adds the scalar values of v as fields, the names of nested values
+// are joined with underscores.  Bytes and timestamps are skipped.
+func flatten(fields map[string]interface{}, name string, v interface{}) {
+	join := func(key string) string {
+		if name == "" {
+			return key
+		}
+		return name + "_" + key
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			flatten(fields, join(key), v[key])
+		}
+	case []interface{}:
+		for i, item := range v {
+			flatten(fields, join(strconv.Itoa(i)), item)
+		}
+	case int64, uint64, float64, string, bool:
+		if name != "" {
+			fields[name] = v
+		}
+	}
+}
+
+func tagValue(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
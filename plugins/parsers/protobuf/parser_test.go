@@ -0,0 +1,277 @@
+package protobuf
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func DefaultTime() time.Time {
+	return time.Unix(42, 0)
+}
+
+func field(name string, number int32, t descriptor.FieldDescriptorProto_Type, typeName string) *descriptor.FieldDescriptorProto {
+	f := &descriptor.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Type:   t.Enum(),
+		Label:  descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+	}
+	if typeName != "" {
+		f.TypeName = proto.String(typeName)
+	}
+	return f
+}
+
+func repeated(f *descriptor.FieldDescriptorProto) *descriptor.FieldDescriptorProto {
+	f.Label = descriptor.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	return f
+}
+
+// testDescriptor describes:
+//
+//	enum Region { UNKNOWN = 0; US_EAST = 1; }
+//	message Host { string name = 1; Region region = 2; }
+//	message Reading {
+//	  string name = 1;
+//	  Host host = 2;
+//	  double temperature = 3;
+//	  int64 count = 4;
+//	  sint32 offset = 5;
+//	  repeated float values = 6;
+//	  google.protobuf.Timestamp time = 7;
+//	  map<string, string> labels = 8;
+//	  bool ok = 9;
+//	  fixed64 id = 10;
+//	}
+func testDescriptor() *descriptor.FileDescriptorSet {
+	return &descriptor.FileDescriptorSet{
+		File: []*descriptor.FileDescriptorProto{
+			{
+				Name:    proto.String("google/protobuf/timestamp.proto"),
+				Package: proto.String("google.protobuf"),
+				MessageType: []*descriptor.DescriptorProto{
+					{
+						Name: proto.String("Timestamp"),
+						Field: []*descriptor.FieldDescriptorProto{
+							field("seconds", 1, descriptor.FieldDescriptorProto_TYPE_INT64, ""),
+							field("nanos", 2, descriptor.FieldDescriptorProto_TYPE_INT32, ""),
+						},
+					},
+				},
+			},
+			{
+				Name:    proto.String("test.proto"),
+				Package: proto.String("test"),
+				EnumType: []*descriptor.EnumDescriptorProto{
+					{
+						Name: proto.String("Region"),
+						Value: []*descriptor.EnumValueDescriptorProto{
+							{Name: proto.String("UNKNOWN"), Number: proto.Int32(0)},
+							{Name: proto.String("US_EAST"), Number: proto.Int32(1)},
+						},
+					},
+				},
+				MessageType: []*descriptor.DescriptorProto{
+					{
+						Name: proto.String("Host"),
+						Field: []*descriptor.FieldDescriptorProto{
+							field("name", 1, descriptor.FieldDescriptorProto_TYPE_STRING, ""),
+							field("region", 2, descriptor.FieldDescriptorProto_TYPE_ENUM, ".test.Region"),
+						},
+					},
+					{
+						Name: proto.String("Reading"),
+						Field: []*descriptor.FieldDescriptorProto{
+							field("name", 1, descriptor.FieldDescriptorProto_TYPE_STRING, ""),
+							field("host", 2, descriptor.FieldDescriptorProto_TYPE_MESSAGE, ".test.Host"),
+							field("temperature", 3, descriptor.FieldDescriptorProto_TYPE_DOUBLE, ""),
+							field("count", 4, descriptor.FieldDescriptorProto_TYPE_INT64, ""),
+							field("offset", 5, descriptor.FieldDescriptorProto_TYPE_SINT32, ""),
+							repeated(field("values", 6, descriptor.FieldDescriptorProto_TYPE_FLOAT, "")),
+							field("time", 7, descriptor.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Timestamp"),
+							repeated(field("labels", 8, descriptor.FieldDescriptorProto_TYPE_MESSAGE, ".test.Reading.LabelsEntry")),
+							field("ok", 9, descriptor.FieldDescriptorProto_TYPE_BOOL, ""),
+							field("id", 10, descriptor.FieldDescriptorProto_TYPE_FIXED64, ""),
+						},
+						NestedType: []*descriptor.DescriptorProto{
+							{
+								Name: proto.String("LabelsEntry"),
+								Field: []*descriptor.FieldDescriptorProto{
+									field("key", 1, descriptor.FieldDescriptorProto_TYPE_STRING, ""),
+									field("value", 2, descriptor.FieldDescriptorProto_TYPE_STRING, ""),
+								},
+								Options: &descriptor.MessageOptions{MapEntry: proto.Bool(true)},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// encoder writes messages in the wire format.
+type encoder struct {
+	*proto.Buffer
+}
+
+func newEncoder() *encoder {
+	return &encoder{proto.NewBuffer(nil)}
+}
+
+func (e *encoder) key(number int, wireType int) *encoder {
+	e.EncodeVarint(uint64(number)<<3 | uint64(wireType))
+	return e
+}
+
+func (e *encoder) varint(number int, x uint64) *encoder {
+	e.key(number, wireVarint).EncodeVarint(x)
+	return e
+}
+
+func (e *encoder) bytes(number int, b []byte) *encoder {
+	e.key(number, wireBytes).EncodeRawBytes(b)
+	return e
+}
+
+func (e *encoder) string(number int, s string) *encoder {
+	return e.bytes(number, []byte(s))
+}
+
+func testMessage() []byte {
+	host := newEncoder().string(1, "server01").varint(2, 1)
+	timestamp := newEncoder().varint(1, 1532459904).varint(2, 500)
+	label := newEncoder().string(1, "env").string(2, "prod")
+
+	values := proto.NewBuffer(nil)
+	values.EncodeFixed32(uint64(math.Float32bits(1.5)))
+	values.EncodeFixed32(uint64(math.Float32bits(2.5)))
+
+	e := newEncoder()
+	e.string(1, "weather")
+	e.bytes(2, host.Bytes())
+	e.key(3, wireFixed64).EncodeFixed64(math.Float64bits(21.5))
+	e.varint(4, uint64(1<<64-3)) // -3
+	e.key(5, wireVarint).EncodeZigzag32(uint64(-7 & 0xffffffff))
+	e.bytes(6, values.Bytes())
+	e.bytes(7, timestamp.Bytes())
+	e.bytes(8, label.Bytes())
+	e.varint(9, 1)
+	e.key(10, wireFixed64).EncodeFixed64(math.MaxUint64)
+	e.varint(99, 12345) // unknown field
+	return e.Bytes()
+}
+
+func newTestParser(t *testing.T) *Parser {
+	p := &Parser{
+		MetricName:  "protobuf",
+		MessageType: "test.Reading",
+		Now:         DefaultTime,
+	}
+	require.NoError(t, p.setDescriptor(testDescriptor()))
+	return p
+}
+
+func TestParseAllFields(t *testing.T) {
+	p := newTestParser(t)
+
+	metrics, err := p.Parse(testMessage())
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+
+	m := metrics[0]
+	assert.Equal(t, "protobuf", m.Name())
+	assert.Equal(t, map[string]string{}, m.Tags())
+	assert.Equal(t, map[string]interface{}{
+		"name":        "weather",
+		"host_name":   "server01",
+		"host_region": "US_EAST",
+		"temperature": 21.5,
+		"count":       int64(-3),
+		"offset":      int64(-7),
+		"values_0":    1.5,
+		"values_1":    2.5,
+		"labels_env":  "prod",
+		"ok":          true,
+		"id":          uint64(math.MaxUint64),
+	}, m.Fields())
+	assert.Equal(t, DefaultTime(), m.Time())
+}
+
+func TestParsePaths(t *testing.T) {
+	p := newTestParser(t)
+	p.MeasurementPath = "name"
+	p.TagPaths = []string{"host.name", "host.region", "labels.env"}
+	p.FieldPaths = []string{"temperature", "values"}
+	p.TimestampPath = "time"
+	p.DefaultTags = map[string]string{"source": "kafka"}
+
+	m, err := p.ParseLine(string(testMessage()))
+	require.NoError(t, err)
+	assert.Equal(t, "weather", m.Name())
+	assert.Equal(t, map[string]string{
+		"source":      "kafka",
+		"host_name":   "server01",
+		"host_region": "US_EAST",
+		"labels_env":  "prod",
+	}, m.Tags())
+	assert.Equal(t, map[string]interface{}{
+		"temperature": 21.5,
+		"values_0":    1.5,
+		"values_1":    2.5,
+	}, m.Fields())
+	assert.Equal(t, time.Unix(1532459904, 500), m.Time())
+}
+
+func TestParseTimestampFormat(t *testing.T) {
+	p := newTestParser(t)
+	p.TimestampPath = "count"
+	p.TimestampFormat = "unix"
+
+	m, err := p.ParseLine(string(newEncoder().varint(4, 1532459904).Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1532459904), m.Time().Unix())
+
+	_, err = p.ParseLine(string(newEncoder().string(1, "missing").Bytes()))
+	require.Error(t, err)
+}
+
+func TestParseTruncated(t *testing.T) {
+	p := newTestParser(t)
+
+	buf := testMessage()
+	_, err := p.Parse(buf[:len(buf)-1])
+	require.Error(t, err)
+}
+
+func TestInit(t *testing.T) {
+	buf, err := proto.Marshal(testDescriptor())
+	require.NoError(t, err)
+
+	f, err := ioutil.TempFile("", "descriptor")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.Write(buf)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	p := &Parser{
+		DescriptorFile: f.Name(),
+		MessageType:    "test.Reading",
+	}
+	require.NoError(t, p.Init())
+
+	p.MessageType = "test.Missing"
+	require.Error(t, p.Init())
+
+	p.DescriptorFile = ""
+	require.Error(t, p.Init())
+}
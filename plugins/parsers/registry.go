@@ -2,16 +2,24 @@ package parsers
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/influxdata/telegraf"
 
 	"github.com/influxdata/telegraf/plugins/parsers/collectd"
+	"github.com/influxdata/telegraf/plugins/parsers/csv"
 	"github.com/influxdata/telegraf/plugins/parsers/dropwizard"
 	"github.com/influxdata/telegraf/plugins/parsers/graphite"
+	"github.com/influxdata/telegraf/plugins/parsers/grok"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
+	"github.com/influxdata/telegraf/plugins/parsers/logfmt"
+	"github.com/influxdata/telegraf/plugins/parsers/msgpack"
 	"github.com/influxdata/telegraf/plugins/parsers/nagios"
+	"github.com/influxdata/telegraf/plugins/parsers/prometheus"
+	"github.com/influxdata/telegraf/plugins/parsers/protobuf"
 	"github.com/influxdata/telegraf/plugins/parsers/value"
+	"github.com/influxdata/telegraf/plugins/parsers/wavefront"
 )
 
 // ParserInput is an interface for input plugins that are able to parse
@@ -46,7 +54,8 @@ type Parser interface {
 // Config is a struct that covers the data types needed for all parser types,
 // and can be used to instantiate _any_ of the parsers.
 type Config struct {
-	// Dataformat can be one of: json, influx, graphite, value, nagios
+	// Dataformat can be one of: json, influx, graphite, value, nagios, csv,
+	// logfmt, grok, prometheus, msgpack, protobuf, wavefront
 	DataFormat string
 
 	// Separator only applied to Graphite data.
@@ -54,8 +63,17 @@ type Config struct {
 	// Templates only apply to Graphite data.
 	Templates []string
 
-	// TagKeys only apply to JSON data
+	// TagKeys only apply to JSON and logfmt data
 	TagKeys []string
+	// Names of string values to keep as fields; JSON only
+	JSONStringFields []string
+	// Path selecting the object or array of objects to parse; JSON only
+	JSONQuery string
+	// Paths of the measurement name and timestamp, and the format of the
+	// timestamp; JSON only
+	JSONNameKey    string
+	JSONTimeKey    string
+	JSONTimeFormat string
 	// MetricName applies to JSON & value. This will be the name of the measurement.
 	MetricName string
 
@@ -87,6 +105,40 @@ type Config struct {
 	// an optional map containing tag names as keys and json paths to retrieve the tag values from as values
 	// used if TagsPath is empty or doesn't return any tags
 	DropwizardTagPathsMap map[string]string
+
+	// CSV configuration
+	CSVHeaderRowCount    int
+	CSVSkipRows          int
+	CSVSkipColumns       int
+	CSVDelimiter         string
+	CSVComment           string
+	CSVTrimSpace         bool
+	CSVColumnNames       []string
+	CSVColumnTypes       []string
+	CSVTagColumns        []string
+	CSVMeasurementColumn string
+	CSVTimestampColumn   string
+	CSVTimestampFormat   string
+
+	// Key and format of the timestamp for logfmt data
+	LogfmtTimestampKey    string
+	LogfmtTimestampFormat string
+
+	// grok patterns, custom patterns and timezone
+	GrokPatterns           []string
+	GrokCustomPatterns     string
+	GrokCustomPatternFiles []string
+	GrokTimezone           string
+
+	// Descriptor set, message type and paths of the values to map to the
+	// measurement, tags, fields and timestamp; protobuf only
+	ProtobufDescriptor      string
+	ProtobufMessageType     string
+	ProtobufMeasurementPath string
+	ProtobufTagPaths        []string
+	ProtobufFieldPaths      []string
+	ProtobufTimestampPath   string
+	ProtobufTimestampFormat string
 }
 
 // NewParser returns a Parser interface based on the given config.
@@ -95,8 +147,14 @@ func NewParser(config *Config) (Parser, error) {
 	var parser Parser
 	switch config.DataFormat {
 	case "json":
-		parser, err = NewJSONParser(config.MetricName,
-			config.TagKeys, config.DefaultTags)
+		parser, err = NewJSONParserWithOptions(config.MetricName,
+			config.TagKeys,
+			config.JSONStringFields,
+			config.JSONQuery,
+			config.JSONNameKey,
+			config.JSONTimeKey,
+			config.JSONTimeFormat,
+			config.DefaultTags)
 	case "value":
 		parser, err = NewValueParser(config.MetricName,
 			config.DataType, config.DefaultTags)
@@ -120,6 +178,50 @@ func NewParser(config *Config) (Parser, error) {
 			config.DefaultTags,
 			config.Separator,
 			config.Templates)
+	case "csv":
+		parser, err = NewCSVParser(config.MetricName,
+			config.CSVHeaderRowCount,
+			config.CSVSkipRows,
+			config.CSVSkipColumns,
+			config.CSVDelimiter,
+			config.CSVComment,
+			config.CSVTrimSpace,
+			config.CSVColumnNames,
+			config.CSVColumnTypes,
+			config.CSVTagColumns,
+			config.CSVMeasurementColumn,
+			config.CSVTimestampColumn,
+			config.CSVTimestampFormat,
+			config.DefaultTags)
+	case "logfmt":
+		parser, err = NewLogFmtParser(config.MetricName,
+			config.TagKeys,
+			config.LogfmtTimestampKey,
+			config.LogfmtTimestampFormat,
+			config.DefaultTags)
+	case "grok":
+		parser, err = NewGrokParser(config.MetricName,
+			config.GrokPatterns,
+			config.GrokCustomPatterns,
+			config.GrokCustomPatternFiles,
+			config.GrokTimezone,
+			config.DefaultTags)
+	case "prometheus":
+		parser, err = NewPrometheusParser(config.DefaultTags)
+	case "wavefront":
+		parser, err = NewWavefrontParser(config.DefaultTags)
+	case "msgpack":
+		parser, err = NewMsgpackParser(config.MetricName, config.DefaultTags)
+	case "protobuf":
+		parser, err = NewProtobufParser(config.MetricName,
+			config.ProtobufDescriptor,
+			config.ProtobufMessageType,
+			config.ProtobufMeasurementPath,
+			config.ProtobufTagPaths,
+			config.ProtobufFieldPaths,
+			config.ProtobufTimestampPath,
+			config.ProtobufTimestampFormat,
+			config.DefaultTags)
 	default:
 		err = fmt.Errorf("Invalid data format: %s", config.DataFormat)
 	}
@@ -139,6 +241,159 @@ func NewJSONParser(
 	return parser, nil
 }
 
+// NewJSONParserWithOptions returns a json parser supporting string fields,
+// queries and the name and time keys.
+func NewJSONParserWithOptions(
+	metricName string,
+	tagKeys []string,
+	stringFields []string,
+	query string,
+	nameKey string,
+	timeKey string,
+	timeFormat string,
+	defaultTags map[string]string,
+) (Parser, error) {
+	if timeKey != "" && timeFormat == "" {
+		return nil, fmt.Errorf("use of json_time_key requires json_time_format")
+	}
+
+	parser := &json.JSONParser{
+		MetricName:   metricName,
+		TagKeys:      tagKeys,
+		StringFields: stringFields,
+		Query:        query,
+		NameKey:      nameKey,
+		TimeKey:      timeKey,
+		TimeFormat:   timeFormat,
+		DefaultTags:  defaultTags,
+	}
+	return parser, nil
+}
+
+// NewCSVParser returns a csv parser with the given options.
+func NewCSVParser(metricName string,
+	headerRowCount int,
+	skipRows int,
+	skipColumns int,
+	delimiter string,
+	comment string,
+	trimSpace bool,
+	columnNames []string,
+	columnTypes []string,
+	tagColumns []string,
+	measurementColumn string,
+	timestampColumn string,
+	timestampFormat string,
+	defaultTags map[string]string) (Parser, error) {
+
+	if headerRowCount == 0 && len(columnNames) == 0 {
+		return nil, fmt.Errorf("there must be a header if `csv_column_names` is not specified")
+	}
+
+	if len(columnTypes) > 0 && len(columnNames) > 0 && len(columnTypes) != len(columnNames) {
+		return nil, fmt.Errorf("csv_column_names field count doesn't match with csv_column_types")
+	}
+
+	parser := &csv.Parser{
+		MetricName:        metricName,
+		HeaderRowCount:    headerRowCount,
+		SkipRows:          skipRows,
+		SkipColumns:       skipColumns,
+		Delimiter:         delimiter,
+		Comment:           comment,
+		TrimSpace:         trimSpace,
+		ColumnNames:       columnNames,
+		ColumnTypes:       columnTypes,
+		TagColumns:        tagColumns,
+		MeasurementColumn: measurementColumn,
+		TimestampColumn:   timestampColumn,
+		TimestampFormat:   timestampFormat,
+		DefaultTags:       defaultTags,
+		TimeFunc:          time.Now,
+	}
+
+	return parser, nil
+}
+
+// NewLogFmtParser returns a logfmt parser with the given options.
+func NewLogFmtParser(
+	metricName string,
+	tagKeys []string,
+	timestampKey string,
+	timestampFormat string,
+	defaultTags map[string]string,
+) (Parser, error) {
+	parser := logfmt.NewParser(metricName, defaultTags)
+	parser.TagKeys = tagKeys
+	parser.TimestampKey = timestampKey
+	if timestampFormat != "" {
+		parser.TimestampFormat = timestampFormat
+	}
+	return parser, nil
+}
+
+// NewGrokParser returns a grok parser with the given patterns.
+func NewGrokParser(metricName string,
+	patterns []string,
+	customPatterns string,
+	customPatternFiles []string,
+	timezone string,
+	defaultTags map[string]string) (Parser, error) {
+	parser := &grok.Parser{
+		Measurement:        metricName,
+		Patterns:           patterns,
+		CustomPatterns:     customPatterns,
+		CustomPatternFiles: customPatternFiles,
+		Timezone:           timezone,
+		DefaultTags:        defaultTags,
+	}
+
+	err := parser.Compile()
+	return parser, err
+}
+
+func NewPrometheusParser(defaultTags map[string]string) (Parser, error) {
+	return &prometheus.Parser{
+		DefaultTags: defaultTags,
+	}, nil
+}
+
+// NewWavefrontParser returns a wavefront parser with the given default tags.
+func NewWavefrontParser(defaultTags map[string]string) (Parser, error) {
+	return wavefront.NewParser(defaultTags), nil
+}
+
+func NewMsgpackParser(metricName string, defaultTags map[string]string) (Parser, error) {
+	return msgpack.NewParser(metricName, defaultTags), nil
+}
+
+// NewProtobufParser returns a protobuf parser of the message type read from
+// the descriptor file.
+func NewProtobufParser(metricName string,
+	descriptorFile string,
+	messageType string,
+	measurementPath string,
+	tagPaths []string,
+	fieldPaths []string,
+	timestampPath string,
+	timestampFormat string,
+	defaultTags map[string]string) (Parser, error) {
+	parser := &protobuf.Parser{
+		MetricName:      metricName,
+		DescriptorFile:  descriptorFile,
+		MessageType:     messageType,
+		MeasurementPath: measurementPath,
+		TagPaths:        tagPaths,
+		FieldPaths:      fieldPaths,
+		TimestampPath:   timestampPath,
+		TimestampFormat: timestampFormat,
+		DefaultTags:     defaultTags,
+	}
+
+	err := parser.Init()
+	return parser, err
+}
+
 func NewNagiosParser() (Parser, error) {
 	return &nagios.NagiosParser{}, nil
 }
@@ -0,0 +1,174 @@
+package wavefront
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+var (
+	ErrNoMetric = fmt.Errorf("no metric in line")
+)
+
+// Parser decodes the Wavefront data format:
+//
+//	<metricName> <metricValue> [<timestamp>] source=<source> [pointTags]
+//
+// Each point becomes a metric named after the metric name with a single
+// value field, the source and point tags become tags.
+type Parser struct {
+	DefaultTags map[string]string
+	Now         func() time.Time
+}
+
+// NewParser creates a parser.
+func NewParser(defaultTags map[string]string) *Parser {
+	return &Parser{
+		DefaultTags: defaultTags,
+		Now:         time.Now,
+	}
+}
+
+// Parse converts a buffer of points separated by newlines to metrics.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	now := p.Now()
+
+	metrics := make([]telegraf.Metric, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		m, err := p.parseLine(line, now)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// ParseLine converts a single point to a metric.
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, ErrNoMetric
+	}
+	return metrics[0], nil
+}
+
+// SetDefaultTags adds tags to the metrics outputs of Parse and ParseLine.
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+func (p *Parser) parseLine(line string, now time.Time) (telegraf.Metric, error) {
+	tokens, err := split(line)
+	if err != nil {
+		return nil, fmt.Errorf("wavefront: %v: %q", err, line)
+	}
+	if len(tokens) < 2 {
+		return nil, fmt.Errorf("wavefront: missing metric value: %q", line)
+	}
+
+	name := tokens[0].text
+	value, err := strconv.ParseFloat(tokens[1].text, 64)
+	if err != nil {
+		return nil, fmt.Errorf("wavefront: invalid metric value %q: %q", tokens[1].text, line)
+	}
+	tokens = tokens[2:]
+
+	timestamp := now
+	if len(tokens) > 0 && !tokens[0].tag {
+		sec, err := strconv.ParseInt(tokens[0].text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("wavefront: invalid timestamp %q: %q", tokens[0].text, line)
+		}
+		timestamp = time.Unix(sec, 0)
+		tokens = tokens[1:]
+	}
+
+	tags := make(map[string]string)
+	for k, v := range p.DefaultTags {
+		tags[k] = v
+	}
+	for _, token := range tokens {
+		if !token.tag {
+			return nil, fmt.Errorf("wavefront: invalid point tag %q: %q", token.text, line)
+		}
+		tags[token.key] = token.text
+	}
+
+	return metric.New(name, tags, map[string]interface{}{"value": value}, timestamp)
+}
+
+// token is a word of a line, either a plain value or a point tag.
+type token struct {
+	key  string
+	text string
+	tag  bool
+}
+
+// split splits a line into tokens separated by whitespace.  Double quotes
+// group characters including whitespace, with backslash escaping the next
+// character inside quotes.  Tokens with an unquoted equal sign are point
+// tags.
+func split(line string) ([]token, error) {
+	var tokens []token
+	var buf bytes.Buffer
+	var current token
+	inToken, inQuotes := false, false
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case inQuotes && c == '\\' && i+1 < len(line):
+			i++
+			buf.WriteByte(line[i])
+		case c == '"':
+			inQuotes = !inQuotes
+			inToken = true
+		case inQuotes:
+			buf.WriteByte(c)
+		case c == ' ' || c == '\t':
+			if inToken {
+				current.text = buf.String()
+				tokens = append(tokens, current)
+				current = token{}
+				buf.Reset()
+				inToken = false
+			}
+		case c == '=' && !current.tag:
+			current.key = buf.String()
+			current.tag = true
+			buf.Reset()
+			inToken = true
+		default:
+			buf.WriteByte(c)
+			inToken = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inToken {
+		current.text = buf.String()
+		tokens = append(tokens, current)
+	}
+	return tokens, nil
+}
@@ -0,0 +1,113 @@
+package wavefront
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func DefaultTime() time.Time {
+	return time.Unix(42, 0)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		metric   string
+		value    float64
+		tags     map[string]string
+		expected time.Time
+	}{
+		{
+			name:     "name and value",
+			input:    "test.metric 1",
+			metric:   "test.metric",
+			value:    1,
+			tags:     map[string]string{},
+			expected: DefaultTime(),
+		},
+		{
+			name:     "timestamp and source",
+			input:    "test.metric 1.5 1530939936 source=mysource",
+			metric:   "test.metric",
+			value:    1.5,
+			tags:     map[string]string{"source": "mysource"},
+			expected: time.Unix(1530939936, 0),
+		},
+		{
+			name:   "point tags",
+			input:  `test.metric -1 source=mysource tag2=value2 "tag 3"="value 3"`,
+			metric: "test.metric",
+			value:  -1,
+			tags: map[string]string{
+				"source": "mysource",
+				"tag2":   "value2",
+				"tag 3":  "value 3",
+			},
+			expected: DefaultTime(),
+		},
+		{
+			name:   "quoted name and values",
+			input:  `"test.metric" 1e3 1530939936 source="my source" tag="with \"quotes\" and = sign"`,
+			metric: "test.metric",
+			value:  1000,
+			tags: map[string]string{
+				"source": "my source",
+				"tag":    `with "quotes" and = sign`,
+			},
+			expected: time.Unix(1530939936, 0),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(nil)
+			p.Now = DefaultTime
+
+			m, err := p.ParseLine(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.metric, m.Name())
+			assert.Equal(t, map[string]interface{}{"value": tt.value}, m.Fields())
+			assert.Equal(t, tt.tags, m.Tags())
+			assert.Equal(t, tt.expected, m.Time())
+		})
+	}
+}
+
+func TestParseMultipleLines(t *testing.T) {
+	p := NewParser(map[string]string{"relay": "telegraf"})
+	p.Now = DefaultTime
+
+	metrics, err := p.Parse([]byte("# comment\ncpu.idle 91.5 source=a\r\n\nmem.used 12 source=a\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	assert.Equal(t, "cpu.idle", metrics[0].Name())
+	assert.Equal(t, "mem.used", metrics[1].Name())
+	assert.Equal(t, map[string]string{"relay": "telegraf", "source": "a"}, metrics[1].Tags())
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "missing value", input: "test.metric"},
+		{name: "invalid value", input: "test.metric abc source=a"},
+		{name: "invalid timestamp", input: "test.metric 1 yesterday source=a"},
+		{name: "value after tags", input: "test.metric 1 source=a 1530939936"},
+		{name: "unterminated quote", input: `test.metric 1 source="a`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(nil)
+			_, err := p.ParseLine(tt.input)
+			require.Error(t, err)
+		})
+	}
+
+	p := NewParser(nil)
+	_, err := p.ParseLine("")
+	require.Equal(t, ErrNoMetric, err)
+}
@@ -1,9 +1,14 @@
 package all
 
 import (
+	_ "github.com/influxdata/telegraf/plugins/processors/cardinality"
 	_ "github.com/influxdata/telegraf/plugins/processors/converter"
+	_ "github.com/influxdata/telegraf/plugins/processors/date"
+	_ "github.com/influxdata/telegraf/plugins/processors/lookup"
 	_ "github.com/influxdata/telegraf/plugins/processors/override"
+	_ "github.com/influxdata/telegraf/plugins/processors/parser"
 	_ "github.com/influxdata/telegraf/plugins/processors/printer"
 	_ "github.com/influxdata/telegraf/plugins/processors/regex"
+	_ "github.com/influxdata/telegraf/plugins/processors/strings"
 	_ "github.com/influxdata/telegraf/plugins/processors/topk"
 )
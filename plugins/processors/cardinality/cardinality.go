@@ -0,0 +1,278 @@
+package cardinality
+
+import (
+	"log"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+const sampleConfig = `
+  ## Maximum number of distinct series (measurement + tag set) allowed per
+  ## measurement within the window.
+  limit = 10000
+
+  ## Series that have not been seen for this long no longer count towards
+  ## the limit.
+  # window = "1h"
+
+  ## What to do with metrics of a new series once the limit is reached:
+  ##   drop      - drop the metric
+  ##   strip_tag - remove the tag with the most distinct values
+  ##   collapse  - replace the value of the tag with the most distinct values
+  ##               with collapse_value
+  # action = "drop"
+
+  ## Tags that may be stripped or collapsed; if empty any tag may be chosen.
+  # tags = []
+
+  ## Value used by the collapse action.
+  # collapse_value = "other"
+
+  ## Name of this processor in the internal_cardinality metrics, defaults to
+  ## the position of the processor among the cardinality processors.
+  # alias = ""
+`
+
+const (
+	actionDrop     = "drop"
+	actionStripTag = "strip_tag"
+	actionCollapse = "collapse"
+)
+
+type Cardinality struct {
+	Limit         int               `toml:"limit"`
+	Window        internal.Duration `toml:"window"`
+	Action        string            `toml:"action"`
+	Tags          []string          `toml:"tags"`
+	CollapseValue string            `toml:"collapse_value"`
+	Alias         string            `toml:"alias"`
+
+	measurements map[string]*measurement
+	lastPrune    time.Time
+	now          func() time.Time
+
+	registered   bool
+	series       selfstat.Stat
+	measurementN selfstat.Stat
+	breaches     selfstat.Stat
+	dropped      selfstat.Stat
+	modified     selfstat.Stat
+}
+
+// measurement tracks the series and tag values seen for a single measurement.
+type measurement struct {
+	series    map[uint64]time.Time
+	tagValues map[string]map[string]time.Time
+	warned    bool
+}
+
+// instances counts the processors created, to give each a default alias.
+var instances int32
+
+func New() *Cardinality {
+	return &Cardinality{
+		Limit:         10000,
+		Window:        internal.Duration{Duration: time.Hour},
+		Action:        actionDrop,
+		CollapseValue: "other",
+		Alias:         strconv.Itoa(int(atomic.AddInt32(&instances, 1))),
+		measurements:  make(map[string]*measurement),
+		now:           time.Now,
+	}
+}
+
+// register creates the stats of the processor.  This is done on first use
+// rather than in New so the configured alias is known.
+func (c *Cardinality) register() {
+	tags := map[string]string{"alias": c.Alias}
+	c.series = selfstat.Register("cardinality", "series", tags)
+	c.measurementN = selfstat.Register("cardinality", "measurements", tags)
+	c.breaches = selfstat.Register("cardinality", "limit_breaches", tags)
+	c.dropped = selfstat.Register("cardinality", "metrics_dropped", tags)
+	c.modified = selfstat.Register("cardinality", "metrics_modified", tags)
+	c.registered = true
+}
+
+func (c *Cardinality) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *Cardinality) Description() string {
+	return "Limit the number of distinct series per measurement"
+}
+
+func (c *Cardinality) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	switch c.Action {
+	case actionDrop, actionStripTag, actionCollapse:
+	default:
+		log.Printf("E! [processors.cardinality] unknown action %q", c.Action)
+		return in
+	}
+	if !c.registered {
+		c.register()
+	}
+
+	now := c.now()
+	c.prune(now)
+
+	out := in[:0]
+	for _, metric := range in {
+		m := c.measurement(metric.Name())
+
+		if c.accept(m, metric, now) {
+			out = append(out, metric)
+			continue
+		}
+
+		c.breaches.Incr(1)
+		if !m.warned {
+			log.Printf("W! [processors.cardinality] measurement %q reached the limit of %d series",
+				metric.Name(), c.Limit)
+			m.warned = true
+		}
+
+		// the reduced series is admitted even over the limit, as reducing the
+		// tag bounds the number of series it adds
+		if c.Action != actionDrop && c.reduce(m, metric) {
+			m.record(metric, now)
+			c.modified.Incr(1)
+			out = append(out, metric)
+			continue
+		}
+		c.dropped.Incr(1)
+	}
+	c.updateStats()
+	return out
+}
+
+func (c *Cardinality) measurement(name string) *measurement {
+	m, ok := c.measurements[name]
+	if !ok {
+		m = &measurement{
+			series:    make(map[uint64]time.Time),
+			tagValues: make(map[string]map[string]time.Time),
+		}
+		c.measurements[name] = m
+	}
+	return m
+}
+
+// accept records the series of metric and reports whether it is allowed,
+// which is the case for known series and new series while under the limit.
+func (c *Cardinality) accept(m *measurement, metric telegraf.Metric, now time.Time) bool {
+	if _, ok := m.series[metric.HashID()]; !ok && len(m.series) >= c.Limit {
+		return false
+	}
+	m.record(metric, now)
+	return true
+}
+
+// record marks the series and tag values of metric as seen.
+func (m *measurement) record(metric telegraf.Metric, now time.Time) {
+	m.series[metric.HashID()] = now
+	for _, tag := range metric.TagList() {
+		values, ok := m.tagValues[tag.Key]
+		if !ok {
+			values = make(map[string]time.Time)
+			m.tagValues[tag.Key] = values
+		}
+		values[tag.Value] = now
+	}
+}
+
+// reduce strips or collapses the tag of metric with the most distinct values
+// and reports whether the metric was changed.
+func (c *Cardinality) reduce(m *measurement, metric telegraf.Metric) bool {
+	key, ok := c.offendingTag(m, metric)
+	if !ok {
+		return false
+	}
+
+	switch c.Action {
+	case actionStripTag:
+		metric.RemoveTag(key)
+	case actionCollapse:
+		metric.AddTag(key, c.CollapseValue)
+	}
+	return true
+}
+
+func (c *Cardinality) offendingTag(m *measurement, metric telegraf.Metric) (string, bool) {
+	candidates := c.Tags
+	if len(candidates) == 0 {
+		candidates = make([]string, 0, len(metric.TagList()))
+		for _, tag := range metric.TagList() {
+			candidates = append(candidates, tag.Key)
+		}
+	}
+
+	var key string
+	max := -1
+	for _, k := range candidates {
+		if !metric.HasTag(k) {
+			continue
+		}
+		if n := len(m.tagValues[k]); n > max {
+			key, max = k, n
+		}
+	}
+	return key, max >= 0
+}
+
+// prune forgets series and tag values not seen within the window, and
+// measurements without series.  To keep the cost of pruning low it runs at
+// most ten times per window.
+func (c *Cardinality) prune(now time.Time) {
+	if now.Sub(c.lastPrune) < c.Window.Duration/10 {
+		return
+	}
+	c.lastPrune = now
+
+	cutoff := now.Add(-c.Window.Duration)
+	for name, m := range c.measurements {
+		m.prune(cutoff)
+		if len(m.series) == 0 {
+			delete(c.measurements, name)
+		}
+	}
+}
+
+func (m *measurement) prune(cutoff time.Time) {
+	for id, seen := range m.series {
+		if seen.Before(cutoff) {
+			delete(m.series, id)
+		}
+	}
+	for key, values := range m.tagValues {
+		for value, seen := range values {
+			if seen.Before(cutoff) {
+				delete(values, value)
+			}
+		}
+		if len(values) == 0 {
+			delete(m.tagValues, key)
+		}
+	}
+	m.warned = false
+}
+
+func (c *Cardinality) updateStats() {
+	var series int
+	for _, m := range c.measurements {
+		series += len(m.series)
+	}
+	c.series.Set(int64(series))
+	c.measurementN.Set(int64(len(c.measurements)))
+}
+
+func init() {
+	processors.Add("cardinality", func() telegraf.Processor {
+		return New()
+	})
+}
@@ -0,0 +1,190 @@
+package cardinality
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/selfstat"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMetric(name string, tags map[string]string) telegraf.Metric {
+	return testutil.MustMetric(name, tags, map[string]interface{}{"value": int64(1)}, time.Now())
+}
+
+func requests(n int) []telegraf.Metric {
+	metrics := make([]telegraf.Metric, 0, n)
+	for i := 0; i < n; i++ {
+		metrics = append(metrics, newMetric("http", map[string]string{
+			"host":       "web01",
+			"request_id": fmt.Sprintf("%d", i),
+		}))
+	}
+	return metrics
+}
+
+func newPlugin(action string, limit int) (*Cardinality, *time.Time) {
+	now := time.Unix(1500000000, 0)
+	plugin := New()
+	plugin.Action = action
+	plugin.Limit = limit
+	plugin.Window.Duration = time.Minute
+	plugin.now = func() time.Time { return now }
+	return plugin, &now
+}
+
+func stat(plugin *Cardinality, field string) int64 {
+	return selfstat.Register("cardinality", field, map[string]string{"alias": plugin.Alias}).Get()
+}
+
+func TestDrop(t *testing.T) {
+	plugin, _ := newPlugin(actionDrop, 3)
+
+	out := plugin.Apply(requests(5)...)
+	require.Len(t, out, 3)
+
+	// known series are still accepted
+	out = plugin.Apply(requests(5)...)
+	require.Len(t, out, 3)
+
+	// other measurements have their own limit
+	out = plugin.Apply(newMetric("cpu", map[string]string{"host": "web01"}))
+	require.Len(t, out, 1)
+}
+
+func TestStripTag(t *testing.T) {
+	plugin, _ := newPlugin(actionStripTag, 3)
+
+	out := plugin.Apply(
+		newMetric("http", map[string]string{"host": "web01"}),
+		newMetric("http", map[string]string{"host": "web01", "request_id": "0"}),
+		newMetric("http", map[string]string{"host": "web01", "request_id": "1"}),
+		newMetric("http", map[string]string{"host": "web01", "request_id": "2"}),
+		newMetric("http", map[string]string{"host": "web01", "request_id": "3"}),
+	)
+	require.Len(t, out, 5)
+
+	assert.Equal(t, map[string]string{"host": "web01", "request_id": "0"}, out[1].Tags())
+	assert.Equal(t, map[string]string{"host": "web01", "request_id": "1"}, out[2].Tags())
+	assert.Equal(t, map[string]string{"host": "web01"}, out[3].Tags())
+	assert.Equal(t, map[string]string{"host": "web01"}, out[4].Tags())
+}
+
+func TestCollapse(t *testing.T) {
+	plugin, _ := newPlugin(actionCollapse, 3)
+
+	other := newMetric("http", map[string]string{"host": "web01", "request_id": "other"})
+	out := plugin.Apply(append([]telegraf.Metric{other}, requests(4)...)...)
+	require.Len(t, out, 5)
+
+	assert.Equal(t, map[string]string{"host": "web01", "request_id": "1"}, out[2].Tags())
+	assert.Equal(t, map[string]string{"host": "web01", "request_id": "other"}, out[3].Tags())
+	assert.Equal(t, map[string]string{"host": "web01", "request_id": "other"}, out[4].Tags())
+}
+
+func TestReducedSeriesOverLimit(t *testing.T) {
+	plugin, _ := newPlugin(actionStripTag, 3)
+
+	out := plugin.Apply(requests(5)...)
+	require.Len(t, out, 5)
+	assert.Equal(t, map[string]string{"host": "web01", "request_id": "2"}, out[2].Tags())
+	assert.Equal(t, map[string]string{"host": "web01"}, out[3].Tags())
+	assert.Equal(t, map[string]string{"host": "web01"}, out[4].Tags())
+	for _, m := range plugin.measurements {
+		assert.Len(t, m.series, 4)
+	}
+}
+
+func TestCollapsedSeriesOverLimit(t *testing.T) {
+	plugin, _ := newPlugin(actionCollapse, 3)
+
+	out := plugin.Apply(requests(5)...)
+	require.Len(t, out, 5)
+	assert.Equal(t, map[string]string{"host": "web01", "request_id": "other"}, out[3].Tags())
+	assert.Equal(t, map[string]string{"host": "web01", "request_id": "other"}, out[4].Tags())
+	assert.Equal(t, int64(2), stat(plugin, "metrics_modified"))
+}
+
+func TestReduceWithoutCandidateTagDrops(t *testing.T) {
+	plugin, _ := newPlugin(actionCollapse, 2)
+	plugin.Tags = []string{"request_id"}
+
+	out := plugin.Apply(
+		newMetric("http", map[string]string{"host": "a"}),
+		newMetric("http", map[string]string{"host": "b"}),
+		newMetric("http", map[string]string{"host": "c"}),
+	)
+	require.Len(t, out, 2)
+}
+
+func TestConfiguredTags(t *testing.T) {
+	plugin, _ := newPlugin(actionStripTag, 2)
+	plugin.Tags = []string{"host"}
+
+	out := plugin.Apply(
+		newMetric("disk", map[string]string{"host": "a", "path": "/"}),
+		newMetric("disk", map[string]string{"path": "/var"}),
+		newMetric("disk", map[string]string{"host": "a", "path": "/var"}),
+	)
+	require.Len(t, out, 3)
+	assert.Equal(t, map[string]string{"path": "/var"}, out[2].Tags())
+
+	// the reduced series is new but still admitted over the limit
+	out = plugin.Apply(newMetric("disk", map[string]string{"host": "b", "path": "/"}))
+	require.Len(t, out, 1)
+	assert.Equal(t, map[string]string{"path": "/"}, out[0].Tags())
+}
+
+func TestWindowExpiry(t *testing.T) {
+	plugin, now := newPlugin(actionDrop, 3)
+
+	out := plugin.Apply(requests(3)...)
+	require.Len(t, out, 3)
+
+	out = plugin.Apply(newMetric("http", map[string]string{"request_id": "new"}))
+	require.Len(t, out, 0)
+
+	*now = now.Add(2 * time.Minute)
+	out = plugin.Apply(newMetric("http", map[string]string{"request_id": "new"}))
+	require.Len(t, out, 1)
+}
+
+func TestPruneMeasurements(t *testing.T) {
+	plugin, now := newPlugin(actionDrop, 3)
+
+	plugin.Apply(newMetric("cpu", map[string]string{"host": "a"}))
+	plugin.Apply(newMetric("mem", map[string]string{"host": "a"}))
+	require.Len(t, plugin.measurements, 2)
+
+	*now = now.Add(2 * time.Minute)
+	plugin.Apply(newMetric("cpu", map[string]string{"host": "a"}))
+	require.Len(t, plugin.measurements, 1)
+}
+
+func TestStats(t *testing.T) {
+	plugin, _ := newPlugin(actionDrop, 2)
+
+	other, _ := newPlugin(actionDrop, 2)
+	other.Apply(newMetric("stats_test", map[string]string{"id": "4"}))
+
+	metrics := []telegraf.Metric{
+		newMetric("stats_test", map[string]string{"id": "1"}),
+		newMetric("stats_test", map[string]string{"id": "2"}),
+		newMetric("stats_test", map[string]string{"id": "3"}),
+	}
+	out := plugin.Apply(metrics...)
+	require.Len(t, out, 2)
+
+	assert.Equal(t, int64(1), stat(plugin, "metrics_dropped"))
+	assert.Equal(t, int64(2), stat(plugin, "series"))
+	assert.Equal(t, int64(1), stat(plugin, "measurements"))
+	assert.Equal(t, int64(1), stat(plugin, "limit_breaches"))
+
+	// each processor has its own stats
+	assert.Equal(t, int64(0), stat(other, "metrics_dropped"))
+	assert.Equal(t, int64(1), stat(other, "series"))
+}
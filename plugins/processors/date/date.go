@@ -0,0 +1,171 @@
+package date
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## Tag or field to create from the metric timestamp.  Only one of tag_key
+  ## and field_key may be set.
+  tag_key = "month"
+  # field_key = "month"
+
+  ## Format of the created value, using the Go reference time
+  ## "Mon Jan 2 15:04:05 MST 2006" or one of "unix", "unix_ms", "unix_us"
+  ## and "unix_ns".  Unix formats create integer fields.
+  date_format = "Jan"
+
+  ## Tag or field to parse into the metric timestamp before any value is
+  ## created.  Only one of parse_tag and parse_field may be set.
+  # parse_field = "timestamp"
+  # parse_tag = "timestamp"
+
+  ## Format of the parsed value, accepts the same values as date_format.
+  # parse_format = "2006-01-02T15:04:05Z07:00"
+
+  ## Remove the parsed tag or field from the metric.
+  # parse_remove = false
+
+  ## Timezone used to create values and to parse values that do not include
+  ## a timezone.  Accepts "UTC", "Local" or an IANA location name such as
+  ## "America/New_York".
+  # timezone = "UTC"
+`
+
+type Date struct {
+	TagKey     string `toml:"tag_key"`
+	FieldKey   string `toml:"field_key"`
+	DateFormat string `toml:"date_format"`
+
+	ParseTag    string `toml:"parse_tag"`
+	ParseField  string `toml:"parse_field"`
+	ParseFormat string `toml:"parse_format"`
+	ParseRemove bool   `toml:"parse_remove"`
+
+	Timezone string `toml:"timezone"`
+
+	location    *time.Location
+	initialized bool
+}
+
+func (d *Date) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *Date) Description() string {
+	return "Create tags or fields from the metric timestamp, or set it from a tag or field"
+}
+
+func (d *Date) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	if !d.initialized {
+		err := d.initialize()
+		if err != nil {
+			log.Printf("E! [processors.date] initialization error: %v", err)
+			return in
+		}
+	}
+
+	for _, metric := range in {
+		if d.ParseTag != "" || d.ParseField != "" {
+			err := d.parse(metric)
+			if err != nil {
+				log.Printf("D! [processors.date] %v", err)
+			}
+		}
+
+		if d.TagKey != "" {
+			metric.AddTag(d.TagKey, fmt.Sprint(format(metric.Time().In(d.location), d.DateFormat)))
+		}
+
+		if d.FieldKey != "" {
+			metric.AddField(d.FieldKey, format(metric.Time().In(d.location), d.DateFormat))
+		}
+	}
+
+	return in
+}
+
+func (d *Date) initialize() error {
+	if d.TagKey != "" && d.FieldKey != "" {
+		return fmt.Errorf("only one of tag_key and field_key may be set")
+	}
+	if d.ParseTag != "" && d.ParseField != "" {
+		return fmt.Errorf("only one of parse_tag and parse_field may be set")
+	}
+	if (d.TagKey != "" || d.FieldKey != "") && d.DateFormat == "" {
+		return fmt.Errorf("date_format is required")
+	}
+	if (d.ParseTag != "" || d.ParseField != "") && d.ParseFormat == "" {
+		return fmt.Errorf("parse_format is required")
+	}
+
+	var err error
+	switch d.Timezone {
+	case "", "UTC":
+		d.location = time.UTC
+	default:
+		d.location, err = time.LoadLocation(d.Timezone)
+		if err != nil {
+			return err
+		}
+	}
+
+	d.initialized = true
+	return nil
+}
+
+func (d *Date) parse(metric telegraf.Metric) error {
+	var value interface{}
+	var ok bool
+	if d.ParseTag != "" {
+		value, ok = metric.GetTag(d.ParseTag)
+	} else {
+		value, ok = metric.GetField(d.ParseField)
+	}
+	if !ok {
+		return nil
+	}
+
+	t, err := internal.ParseTimestampWithLocation(value, d.ParseFormat, d.location)
+	if err != nil {
+		return err
+	}
+	metric.SetTime(t)
+
+	if d.ParseRemove {
+		if d.ParseTag != "" {
+			metric.RemoveTag(d.ParseTag)
+		} else {
+			metric.RemoveField(d.ParseField)
+		}
+	}
+	return nil
+}
+
+// format returns t formatted with layout, unix layouts produce an int64.
+func format(t time.Time, layout string) interface{} {
+	switch layout {
+	case "unix":
+		return t.Unix()
+	case "unix_ms":
+		return t.UnixNano() / int64(time.Millisecond)
+	case "unix_us":
+		return t.UnixNano() / int64(time.Microsecond)
+	case "unix_ns":
+		return t.UnixNano()
+	default:
+		return t.Format(layout)
+	}
+}
+
+func init() {
+	processors.Add("date", func() telegraf.Processor {
+		return &Date{}
+	})
+}
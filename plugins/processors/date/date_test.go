@@ -0,0 +1,158 @@
+package date
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMetric(fields map[string]interface{}, tm time.Time) telegraf.Metric {
+	if fields == nil {
+		fields = map[string]interface{}{"value": int64(42)}
+	}
+	return testutil.MustMetric("foo", map[string]string{}, fields, tm)
+}
+
+func TestTagKey(t *testing.T) {
+	plugin := &Date{
+		TagKey:     "month",
+		DateFormat: "Jan",
+	}
+
+	metrics := plugin.Apply(
+		newMetric(nil, time.Date(2018, time.January, 28, 0, 0, 0, 0, time.UTC)),
+		newMetric(nil, time.Date(2018, time.February, 28, 0, 0, 0, 0, time.UTC)),
+	)
+
+	tag, ok := metrics[0].GetTag("month")
+	require.True(t, ok)
+	assert.Equal(t, "Jan", tag)
+	tag, ok = metrics[1].GetTag("month")
+	require.True(t, ok)
+	assert.Equal(t, "Feb", tag)
+}
+
+func TestFieldKeyUnix(t *testing.T) {
+	plugin := &Date{
+		FieldKey:   "epoch",
+		DateFormat: "unix_ms",
+	}
+
+	tm := time.Date(2018, time.January, 28, 12, 30, 0, 500*int(time.Millisecond), time.UTC)
+	metrics := plugin.Apply(newMetric(nil, tm))
+
+	field, ok := metrics[0].GetField("epoch")
+	require.True(t, ok)
+	assert.Equal(t, tm.UnixNano()/int64(time.Millisecond), field)
+}
+
+func TestTimezone(t *testing.T) {
+	plugin := &Date{
+		TagKey:     "hour_of_day",
+		DateFormat: "15",
+		Timezone:   "America/New_York",
+	}
+
+	metrics := plugin.Apply(newMetric(nil, time.Date(2018, time.January, 28, 12, 0, 0, 0, time.UTC)))
+
+	tag, ok := metrics[0].GetTag("hour_of_day")
+	require.True(t, ok)
+	assert.Equal(t, "07", tag)
+}
+
+func TestInvalidTimezone(t *testing.T) {
+	plugin := &Date{
+		TagKey:     "month",
+		DateFormat: "Jan",
+		Timezone:   "Not/AZone",
+	}
+
+	metrics := plugin.Apply(newMetric(nil, time.Now()))
+	assert.False(t, metrics[0].HasTag("month"))
+}
+
+func TestParseField(t *testing.T) {
+	plugin := &Date{
+		ParseField:  "timestamp",
+		ParseFormat: "2006-01-02 15:04:05",
+		ParseRemove: true,
+		Timezone:    "America/New_York",
+	}
+
+	m := newMetric(map[string]interface{}{
+		"timestamp": "2018-01-28 07:00:00",
+		"value":     int64(42),
+	}, time.Now())
+	metrics := plugin.Apply(m)
+
+	assert.Equal(t, time.Date(2018, time.January, 28, 12, 0, 0, 0, time.UTC).UnixNano(),
+		metrics[0].Time().UnixNano())
+	assert.False(t, metrics[0].HasField("timestamp"))
+}
+
+func TestParseUnixAndFormat(t *testing.T) {
+	plugin := &Date{
+		ParseField:  "ts",
+		ParseFormat: "unix",
+		TagKey:      "weekday",
+		DateFormat:  "Monday",
+	}
+
+	m := newMetric(map[string]interface{}{
+		"ts":    int64(1517097600),
+		"value": int64(42),
+	}, time.Now())
+	metrics := plugin.Apply(m)
+
+	assert.Equal(t, int64(1517097600), metrics[0].Time().Unix())
+	assert.True(t, metrics[0].HasField("ts"))
+	tag, ok := metrics[0].GetTag("weekday")
+	require.True(t, ok)
+	assert.Equal(t, "Sunday", tag)
+}
+
+func TestParseFailureKeepsTime(t *testing.T) {
+	plugin := &Date{
+		ParseField:  "timestamp",
+		ParseFormat: "2006-01-02",
+		ParseRemove: true,
+	}
+
+	tm := time.Date(2018, time.January, 28, 0, 0, 0, 0, time.UTC)
+	m := newMetric(map[string]interface{}{
+		"timestamp": "not a date",
+	}, tm)
+	metrics := plugin.Apply(m)
+
+	assert.Equal(t, tm, metrics[0].Time())
+	assert.True(t, metrics[0].HasField("timestamp"))
+}
+
+func TestParseUnixVariants(t *testing.T) {
+	expected := time.Unix(1517097600, 0)
+	tests := []struct {
+		value  interface{}
+		layout string
+	}{
+		{int64(1517097600), "unix"},
+		{"1517097600", "unix"},
+		{1517097600.0, "unix"},
+		{int64(1517097600000), "unix_ms"},
+		{uint64(1517097600000000), "unix_us"},
+		{"1517097600000000000", "unix_ns"},
+	}
+
+	for _, tt := range tests {
+		plugin := &Date{
+			ParseField:  "ts",
+			ParseFormat: tt.layout,
+		}
+		m := newMetric(map[string]interface{}{"ts": tt.value}, time.Now())
+		metrics := plugin.Apply(m)
+		assert.Equal(t, expected.UnixNano(), metrics[0].Time().UnixNano(), "%v %s", tt.value, tt.layout)
+	}
+}
@@ -0,0 +1,246 @@
+package lookup
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## Path to the lookup table.
+  file = "/etc/telegraf/lookup.csv"
+
+  ## Format of the lookup table, either "csv" or "json".  If unset the
+  ## format is chosen from the file extension.
+  # format = "csv"
+
+  ## Tags whose values are combined to form the lookup key.  Each row of the
+  ## table must provide a column (csv) or string value (json) with the same
+  ## name; every other column is added to matching metrics as a tag.
+  key_tags = ["host"]
+
+  ## Replace tags already present on the metric with values from the table.
+  # overwrite = false
+
+  ## How often to check the file for changes.  The table is reloaded when the
+  ## modification time or size of the file changes.
+  # reload_interval = "30s"
+`
+
+type Lookup struct {
+	File           string            `toml:"file"`
+	Format         string            `toml:"format"`
+	KeyTags        []string          `toml:"key_tags"`
+	Overwrite      bool              `toml:"overwrite"`
+	ReloadInterval internal.Duration `toml:"reload_interval"`
+
+	table     map[string]map[string]string
+	modTime   time.Time
+	size      int64
+	lastCheck time.Time
+}
+
+func New() *Lookup {
+	return &Lookup{
+		ReloadInterval: internal.Duration{Duration: 30 * time.Second},
+	}
+}
+
+func (l *Lookup) SampleConfig() string {
+	return sampleConfig
+}
+
+func (l *Lookup) Description() string {
+	return "Add tags to metrics from a csv or json lookup table keyed on tag values"
+}
+
+func (l *Lookup) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	if err := l.refresh(); err != nil {
+		log.Printf("E! [processors.lookup] %v", err)
+	}
+
+	if len(l.table) == 0 {
+		return in
+	}
+
+	for _, metric := range in {
+		key, ok := l.metricKey(metric)
+		if !ok {
+			continue
+		}
+
+		tags, ok := l.table[key]
+		if !ok {
+			continue
+		}
+
+		for k, v := range tags {
+			if !l.Overwrite && metric.HasTag(k) {
+				continue
+			}
+			metric.AddTag(k, v)
+		}
+	}
+
+	return in
+}
+
+// refresh loads the table on first use, and afterwards once per reload
+// interval if the file has changed on disk.  On error the previously loaded
+// table is kept.
+func (l *Lookup) refresh() error {
+	now := time.Now()
+	if !l.lastCheck.IsZero() && now.Sub(l.lastCheck) < l.ReloadInterval.Duration {
+		return nil
+	}
+	l.lastCheck = now
+
+	stat, err := os.Stat(l.File)
+	if err != nil {
+		return err
+	}
+
+	if l.table != nil && stat.ModTime().Equal(l.modTime) && stat.Size() == l.size {
+		return nil
+	}
+
+	table, err := l.load()
+	if err != nil {
+		return fmt.Errorf("loading %q: %v", l.File, err)
+	}
+
+	l.table = table
+	l.modTime = stat.ModTime()
+	l.size = stat.Size()
+	return nil
+}
+
+func (l *Lookup) load() (map[string]map[string]string, error) {
+	if len(l.KeyTags) == 0 {
+		return nil, fmt.Errorf("key_tags must not be empty")
+	}
+
+	f, err := os.Open(l.File)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	format := l.Format
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(l.File)), ".")
+	}
+
+	var rows []map[string]string
+	switch format {
+	case "csv":
+		rows, err = readCSV(f)
+	case "json":
+		rows, err = readJSON(f)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	table := make(map[string]map[string]string, len(rows))
+	for i, row := range rows {
+		values := make([]string, 0, len(l.KeyTags))
+		for _, k := range l.KeyTags {
+			v, ok := row[k]
+			if !ok {
+				return nil, fmt.Errorf("row %d: missing key %q", i+1, k)
+			}
+			values = append(values, v)
+			delete(row, k)
+		}
+		table[joinKey(values)] = row
+	}
+	return table, nil
+}
+
+func (l *Lookup) metricKey(metric telegraf.Metric) (string, bool) {
+	values := make([]string, 0, len(l.KeyTags))
+	for _, k := range l.KeyTags {
+		v, ok := metric.GetTag(k)
+		if !ok {
+			return "", false
+		}
+		values = append(values, v)
+	}
+	return joinKey(values), true
+}
+
+func joinKey(values []string) string {
+	return strings.Join(values, "\x00")
+}
+
+// readCSV reads a table whose first row names the columns.
+func readCSV(r io.Reader) ([]map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []map[string]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			if record[i] == "" {
+				continue
+			}
+			row[name] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// readJSON reads a table encoded as an array of objects with string values.
+func readJSON(r io.Reader) ([]map[string]string, error) {
+	var rows []map[string]string
+	err := json.NewDecoder(r).Decode(&rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		for k, v := range row {
+			if v == "" {
+				delete(row, k)
+			}
+		}
+	}
+	return rows, nil
+}
+
+func init() {
+	processors.Add("lookup", func() telegraf.Processor {
+		return New()
+	})
+}
@@ -0,0 +1,173 @@
+package lookup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMetric(tags map[string]string) telegraf.Metric {
+	return testutil.MustMetric("cpu",
+		tags,
+		map[string]interface{}{"usage_idle": 42.0},
+		time.Now(),
+	)
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	err := ioutil.WriteFile(path, []byte(content), 0644)
+	require.NoError(t, err)
+	return path
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "lookup")
+	require.NoError(t, err)
+	return dir
+}
+
+func TestCSVLookup(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := writeFile(t, dir, "hosts.csv", `# hostname mapping
+host,team,datacenter
+web01,frontend,us-east
+db01,storage,us-west
+`)
+
+	plugin := New()
+	plugin.File = path
+	plugin.KeyTags = []string{"host"}
+
+	metrics := plugin.Apply(
+		newMetric(map[string]string{"host": "web01"}),
+		newMetric(map[string]string{"host": "unknown"}),
+		newMetric(map[string]string{"cpu": "cpu0"}),
+	)
+	require.Len(t, metrics, 3)
+
+	assert.Equal(t, map[string]string{
+		"host":       "web01",
+		"team":       "frontend",
+		"datacenter": "us-east",
+	}, metrics[0].Tags())
+	assert.Equal(t, map[string]string{"host": "unknown"}, metrics[1].Tags())
+	assert.Equal(t, map[string]string{"cpu": "cpu0"}, metrics[2].Tags())
+}
+
+func TestJSONLookupMultipleKeys(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := writeFile(t, dir, "services.json", `[
+  {"host": "web01", "service": "nginx", "owner": "alice"},
+  {"host": "web01", "service": "redis", "owner": "bob"}
+]`)
+
+	plugin := New()
+	plugin.File = path
+	plugin.KeyTags = []string{"host", "service"}
+
+	metrics := plugin.Apply(
+		newMetric(map[string]string{"host": "web01", "service": "redis"}),
+		newMetric(map[string]string{"host": "web01"}),
+	)
+	require.Len(t, metrics, 2)
+
+	owner, ok := metrics[0].GetTag("owner")
+	require.True(t, ok)
+	assert.Equal(t, "bob", owner)
+	assert.False(t, metrics[1].HasTag("owner"))
+}
+
+func TestOverwrite(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := writeFile(t, dir, "hosts.csv", "host,team\nweb01,frontend\n")
+
+	plugin := New()
+	plugin.File = path
+	plugin.KeyTags = []string{"host"}
+
+	m := plugin.Apply(newMetric(map[string]string{"host": "web01", "team": "ops"}))
+	team, _ := m[0].GetTag("team")
+	assert.Equal(t, "ops", team)
+
+	plugin.Overwrite = true
+	m = plugin.Apply(newMetric(map[string]string{"host": "web01", "team": "ops"}))
+	team, _ = m[0].GetTag("team")
+	assert.Equal(t, "frontend", team)
+}
+
+func TestReloadOnChange(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := writeFile(t, dir, "hosts.csv", "host,team\nweb01,frontend\n")
+
+	plugin := New()
+	plugin.File = path
+	plugin.KeyTags = []string{"host"}
+	plugin.ReloadInterval.Duration = 0
+
+	m := plugin.Apply(newMetric(map[string]string{"host": "web01"}))
+	team, _ := m[0].GetTag("team")
+	assert.Equal(t, "frontend", team)
+
+	writeFile(t, dir, "hosts.csv", "host,team\nweb01,backend\n")
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	m = plugin.Apply(newMetric(map[string]string{"host": "web01"}))
+	team, _ = m[0].GetTag("team")
+	assert.Equal(t, "backend", team)
+}
+
+func TestBadReloadKeepsTable(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := writeFile(t, dir, "hosts.csv", "host,team\nweb01,frontend\n")
+
+	plugin := New()
+	plugin.File = path
+	plugin.KeyTags = []string{"host"}
+	plugin.ReloadInterval.Duration = 0
+
+	plugin.Apply(newMetric(map[string]string{"host": "web01"}))
+
+	writeFile(t, dir, "hosts.csv", "team\nbackend\n")
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	m := plugin.Apply(newMetric(map[string]string{"host": "web01"}))
+	team, _ := m[0].GetTag("team")
+	assert.Equal(t, "frontend", team)
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := writeFile(t, dir, "hosts.txt", "host,team\nweb01,frontend\n")
+
+	plugin := New()
+	plugin.File = path
+	plugin.KeyTags = []string{"host"}
+
+	require.Error(t, plugin.refresh())
+
+	plugin.Format = "csv"
+	plugin.lastCheck = time.Time{}
+	require.NoError(t, plugin.refresh())
+}
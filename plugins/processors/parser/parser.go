@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"log"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+const sampleConfig = `
+  ## The name of the fields whose value will be parsed.
+  parse_fields = ["message"]
+
+  ## The name of the tags whose value will be parsed.
+  # parse_tags = []
+
+  ## If true, incoming metrics are not emitted.
+  # drop_original = false
+
+  ## If set to override, emitted metrics will be merged by overriding the
+  ## original metric using the newly parsed metrics.
+  # merge = "override"
+
+  ## The dataformat to be read from files
+  ## Each data format has its own unique set of configuration options, read
+  ## more about them here:
+  ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
+  data_format = "influx"
+`
+
+type Parser struct {
+	ParseFields  []string `toml:"parse_fields"`
+	ParseTags    []string `toml:"parse_tags"`
+	DropOriginal bool     `toml:"drop_original"`
+	Merge        string   `toml:"merge"`
+
+	parser parsers.Parser
+}
+
+func (p *Parser) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Parser) Description() string {
+	return "Parse a value in a specified field/tag(s) and add the result in a new metric"
+}
+
+func (p *Parser) SetParser(parser parsers.Parser) {
+	p.parser = parser
+}
+
+func (p *Parser) Apply(metrics ...telegraf.Metric) []telegraf.Metric {
+	if p.parser == nil {
+		log.Printf("E! [processors.parser] no parser configured")
+		return metrics
+	}
+
+	results := []telegraf.Metric{}
+	for _, metric := range metrics {
+		newMetrics := []telegraf.Metric{}
+		if !p.DropOriginal {
+			newMetrics = append(newMetrics, metric)
+		}
+
+		for _, key := range p.ParseFields {
+			value, ok := metric.GetField(key)
+			if !ok {
+				continue
+			}
+
+			switch value := value.(type) {
+			case string:
+				newMetrics = append(newMetrics, p.parse(metric, value)...)
+			default:
+				log.Printf("D! [processors.parser] field %q of %q is not a string, skipping", key, metric.Name())
+			}
+		}
+
+		for _, key := range p.ParseTags {
+			value, ok := metric.GetTag(key)
+			if !ok {
+				continue
+			}
+			newMetrics = append(newMetrics, p.parse(metric, value)...)
+		}
+
+		if len(newMetrics) == 0 {
+			continue
+		}
+
+		if p.Merge == "override" {
+			results = append(results, merge(newMetrics[0], newMetrics[1:]))
+		} else {
+			results = append(results, newMetrics...)
+		}
+	}
+	return results
+}
+
+func (p *Parser) parse(original telegraf.Metric, value string) []telegraf.Metric {
+	metrics, err := p.parser.Parse([]byte(value))
+	if err != nil {
+		log.Printf("E! [processors.parser] could not parse value of %q: %v", original.Name(), err)
+		return nil
+	}
+
+	for _, m := range metrics {
+		if m.Name() == "" {
+			m.SetName(original.Name())
+		}
+	}
+	return metrics
+}
+
+// merge adds the tags and fields of metrics to base, later values override
+// earlier ones.  The name and time of base are kept.
+func merge(base telegraf.Metric, metrics []telegraf.Metric) telegraf.Metric {
+	for _, metric := range metrics {
+		for _, field := range metric.FieldList() {
+			base.AddField(field.Key, field.Value)
+		}
+		for _, tag := range metric.TagList() {
+			base.AddTag(tag.Key, tag.Value)
+		}
+	}
+	return base
+}
+
+func init() {
+	processors.Add("parser", func() telegraf.Processor {
+		return &Parser{}
+	})
+}
@@ -0,0 +1,154 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newParser(t *testing.T, config *parsers.Config) parsers.Parser {
+	parser, err := parsers.NewParser(config)
+	require.NoError(t, err)
+	return parser
+}
+
+func newMetric(tags map[string]string, fields map[string]interface{}) telegraf.Metric {
+	return testutil.MustMetric("singleField", tags, fields, time.Unix(0, 0))
+}
+
+func TestParseInfluxDropOriginal(t *testing.T) {
+	plugin := &Parser{
+		ParseFields:  []string{"sample"},
+		DropOriginal: true,
+	}
+	plugin.SetParser(newParser(t, &parsers.Config{DataFormat: "influx"}))
+
+	m := newMetric(
+		map[string]string{"some": "tag"},
+		map[string]interface{}{
+			"sample": `cpu,host=server01 usage_idle=98.5 1500000000000000000`,
+		},
+	)
+
+	output := plugin.Apply(m)
+	require.Len(t, output, 1)
+	assert.Equal(t, "cpu", output[0].Name())
+	assert.Equal(t, map[string]string{"host": "server01"}, output[0].Tags())
+	assert.Equal(t, map[string]interface{}{"usage_idle": 98.5}, output[0].Fields())
+	assert.Equal(t, int64(1500000000000000000), output[0].Time().UnixNano())
+}
+
+func TestParseKeepOriginal(t *testing.T) {
+	plugin := &Parser{
+		ParseFields: []string{"sample"},
+	}
+	plugin.SetParser(newParser(t, &parsers.Config{DataFormat: "influx"}))
+
+	m := newMetric(
+		map[string]string{},
+		map[string]interface{}{
+			"sample": "cpu usage_idle=98.5 1500000000000000000\nmem used=42i 1500000000000000000",
+		},
+	)
+
+	output := plugin.Apply(m)
+	require.Len(t, output, 3)
+	assert.Equal(t, "singleField", output[0].Name())
+	assert.Equal(t, "cpu", output[1].Name())
+	assert.Equal(t, "mem", output[2].Name())
+}
+
+func TestParseJSONMergeOverride(t *testing.T) {
+	plugin := &Parser{
+		ParseFields:  []string{"message"},
+		DropOriginal: false,
+		Merge:        "override",
+	}
+	plugin.SetParser(newParser(t, &parsers.Config{
+		DataFormat: "json",
+		MetricName: "parser",
+		TagKeys:    []string{"level"},
+	}))
+
+	m := newMetric(
+		map[string]string{"host": "web01"},
+		map[string]interface{}{
+			"message": `{"level": "error", "latency": 42, "status": 500}`,
+		},
+	)
+
+	output := plugin.Apply(m)
+	require.Len(t, output, 1)
+	assert.Equal(t, "singleField", output[0].Name())
+	assert.Equal(t, time.Unix(0, 0), output[0].Time())
+	assert.Equal(t, map[string]string{
+		"host":  "web01",
+		"level": "error",
+	}, output[0].Tags())
+	assert.Equal(t, map[string]interface{}{
+		"message": `{"level": "error", "latency": 42, "status": 500}`,
+		"latency": 42.0,
+		"status":  500.0,
+	}, output[0].Fields())
+}
+
+func TestParseTag(t *testing.T) {
+	plugin := &Parser{
+		ParseTags:    []string{"payload"},
+		DropOriginal: true,
+	}
+	plugin.SetParser(newParser(t, &parsers.Config{
+		DataFormat: "value",
+		MetricName: "value",
+		DataType:   "integer",
+	}))
+
+	m := newMetric(
+		map[string]string{"payload": "42"},
+		map[string]interface{}{"value": int64(1)},
+	)
+
+	output := plugin.Apply(m)
+	require.Len(t, output, 1)
+	assert.Equal(t, map[string]interface{}{"value": int64(42)}, output[0].Fields())
+}
+
+func TestParseErrorKeepsOriginal(t *testing.T) {
+	plugin := &Parser{
+		ParseFields: []string{"sample"},
+	}
+	plugin.SetParser(newParser(t, &parsers.Config{DataFormat: "influx"}))
+
+	m := newMetric(
+		map[string]string{},
+		map[string]interface{}{
+			"sample": "not line protocol",
+			"number": int64(1),
+		},
+	)
+
+	output := plugin.Apply(m)
+	require.Len(t, output, 1)
+	assert.Equal(t, m, output[0])
+}
+
+func TestParseNonStringField(t *testing.T) {
+	plugin := &Parser{
+		ParseFields:  []string{"number"},
+		DropOriginal: true,
+	}
+	plugin.SetParser(newParser(t, &parsers.Config{DataFormat: "influx"}))
+
+	m := newMetric(
+		map[string]string{},
+		map[string]interface{}{"number": int64(1)},
+	)
+
+	output := plugin.Apply(m)
+	require.Len(t, output, 0)
+}
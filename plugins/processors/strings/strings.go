@@ -0,0 +1,315 @@
+package strings
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+type Strings struct {
+	Lowercase    []converter `toml:"lowercase"`
+	Uppercase    []converter `toml:"uppercase"`
+	Trim         []converter `toml:"trim"`
+	TrimLeft     []converter `toml:"trim_left"`
+	TrimRight    []converter `toml:"trim_right"`
+	TrimPrefix   []converter `toml:"trim_prefix"`
+	TrimSuffix   []converter `toml:"trim_suffix"`
+	Replace      []converter `toml:"replace"`
+	Left         []converter `toml:"left"`
+	Base64Decode []converter `toml:"base64decode"`
+
+	converters  []converter
+	initialized bool
+}
+
+type ConvertFunc func(s string) string
+
+type converter struct {
+	Measurement string `toml:"measurement"`
+	Tag         string `toml:"tag"`
+	TagKey      string `toml:"tag_key"`
+	Field       string `toml:"field"`
+	FieldKey    string `toml:"field_key"`
+	Dest        string `toml:"dest"`
+	Cutset      string `toml:"cutset"`
+	Prefix      string `toml:"prefix"`
+	Suffix      string `toml:"suffix"`
+	Old         string `toml:"old"`
+	New         string `toml:"new"`
+	Width       int    `toml:"width"`
+
+	fn ConvertFunc
+
+	measurement filter.Filter
+	tag         filter.Filter
+	tagKey      filter.Filter
+	field       filter.Filter
+	fieldKey    filter.Filter
+}
+
+const sampleConfig = `
+  ## Operations are always applied in this fixed order, regardless of the
+  ## order of the sections in the configuration: lowercase, uppercase, trim,
+  ## trim_left, trim_right, trim_prefix, trim_suffix, replace, left and
+  ## base64decode.  Each operation selects what it works
+  ## on using one of measurement, tag, tag_key, field or field_key; all
+  ## selectors accept globs.
+
+  ## Convert a tag value to uppercase
+  # [[processors.strings.uppercase]]
+  #   tag = "method"
+
+  ## Convert a field value to lowercase and store in a new field
+  # [[processors.strings.lowercase]]
+  #   field = "uri_stem"
+  #   dest = "uri_stem_normalised"
+
+  ## Trim leading and trailing whitespace using the default cutset
+  # [[processors.strings.trim]]
+  #   field = "message"
+
+  ## Trim leading characters in cutset
+  # [[processors.strings.trim_left]]
+  #   field = "message"
+  #   cutset = "\t"
+
+  ## Trim trailing characters in cutset
+  # [[processors.strings.trim_right]]
+  #   field = "message"
+  #   cutset = "\r\n"
+
+  ## Trim the given prefix from the field
+  # [[processors.strings.trim_prefix]]
+  #   field = "my_value"
+  #   prefix = "my_"
+
+  ## Trim the given suffix from the field
+  # [[processors.strings.trim_suffix]]
+  #   field = "read_count"
+  #   suffix = "_count"
+
+  ## Replace all non-overlapping instances of old with new
+  # [[processors.strings.replace]]
+  #   measurement = "*"
+  #   old = ":"
+  #   new = "_"
+
+  ## Trims strings based on width
+  # [[processors.strings.left]]
+  #   field = "message"
+  #   width = 10
+
+  ## Decode a base64 encoded utf-8 string
+  # [[processors.strings.base64decode]]
+  #   field = "message"
+`
+
+func (s *Strings) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Strings) Description() string {
+	return "Perform string processing on tags, fields, and measurements"
+}
+
+func (s *Strings) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	if !s.initialized {
+		err := s.compile()
+		if err != nil {
+			log.Printf("E! [processors.strings] initialization error: %v", err)
+			return in
+		}
+	}
+
+	for _, metric := range in {
+		for _, c := range s.converters {
+			c.convert(metric)
+		}
+	}
+
+	return in
+}
+
+func (s *Strings) compile() error {
+	ops := []struct {
+		name       string
+		converters []converter
+		fn         func(c *converter) ConvertFunc
+	}{
+		{"lowercase", s.Lowercase, func(c *converter) ConvertFunc {
+			return strings.ToLower
+		}},
+		{"uppercase", s.Uppercase, func(c *converter) ConvertFunc {
+			return strings.ToUpper
+		}},
+		{"trim", s.Trim, func(c *converter) ConvertFunc {
+			if c.Cutset == "" {
+				return strings.TrimSpace
+			}
+			return func(s string) string { return strings.Trim(s, c.Cutset) }
+		}},
+		{"trim_left", s.TrimLeft, func(c *converter) ConvertFunc {
+			if c.Cutset == "" {
+				return func(s string) string { return strings.TrimLeftFunc(s, unicode.IsSpace) }
+			}
+			return func(s string) string { return strings.TrimLeft(s, c.Cutset) }
+		}},
+		{"trim_right", s.TrimRight, func(c *converter) ConvertFunc {
+			if c.Cutset == "" {
+				return func(s string) string { return strings.TrimRightFunc(s, unicode.IsSpace) }
+			}
+			return func(s string) string { return strings.TrimRight(s, c.Cutset) }
+		}},
+		{"trim_prefix", s.TrimPrefix, func(c *converter) ConvertFunc {
+			return func(s string) string { return strings.TrimPrefix(s, c.Prefix) }
+		}},
+		{"trim_suffix", s.TrimSuffix, func(c *converter) ConvertFunc {
+			return func(s string) string { return strings.TrimSuffix(s, c.Suffix) }
+		}},
+		{"replace", s.Replace, func(c *converter) ConvertFunc {
+			return func(s string) string { return strings.Replace(s, c.Old, c.New, -1) }
+		}},
+		{"left", s.Left, func(c *converter) ConvertFunc {
+			return func(s string) string {
+				if len(s) > c.Width {
+					return s[:c.Width]
+				}
+				return s
+			}
+		}},
+		{"base64decode", s.Base64Decode, func(c *converter) ConvertFunc {
+			return func(s string) string {
+				decoded, err := base64.StdEncoding.DecodeString(s)
+				if err != nil || !utf8.Valid(decoded) {
+					return s
+				}
+				return string(decoded)
+			}
+		}},
+	}
+
+	s.converters = s.converters[:0]
+	for _, op := range ops {
+		for _, c := range op.converters {
+			c := c
+			err := c.compile()
+			if err != nil {
+				return fmt.Errorf("%s: %v", op.name, err)
+			}
+			if op.name == "left" && c.Width <= 0 {
+				return fmt.Errorf("%s: width must be greater than zero", op.name)
+			}
+			c.fn = op.fn(&c)
+			s.converters = append(s.converters, c)
+		}
+	}
+
+	s.initialized = true
+	return nil
+}
+
+func (c *converter) compile() error {
+	var err error
+	if c.measurement, err = compileSelector(c.Measurement); err != nil {
+		return err
+	}
+	if c.tag, err = compileSelector(c.Tag); err != nil {
+		return err
+	}
+	if c.tagKey, err = compileSelector(c.TagKey); err != nil {
+		return err
+	}
+	if c.field, err = compileSelector(c.Field); err != nil {
+		return err
+	}
+	if c.fieldKey, err = compileSelector(c.FieldKey); err != nil {
+		return err
+	}
+
+	if c.measurement == nil && c.tag == nil && c.tagKey == nil &&
+		c.field == nil && c.fieldKey == nil {
+		return fmt.Errorf("one of measurement, tag, tag_key, field or field_key is required")
+	}
+	return nil
+}
+
+func compileSelector(pattern string) (filter.Filter, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return filter.Compile([]string{pattern})
+}
+
+func (c *converter) convert(metric telegraf.Metric) {
+	if c.measurement != nil && c.measurement.Match(metric.Name()) {
+		metric.SetName(c.fn(metric.Name()))
+	}
+
+	if c.tag != nil || c.tagKey != nil {
+		for key, value := range metric.Tags() {
+			c.convertTag(metric, key, value)
+		}
+	}
+
+	if c.field != nil || c.fieldKey != nil {
+		for key, value := range metric.Fields() {
+			c.convertField(metric, key, value)
+		}
+	}
+}
+
+func (c *converter) convertTag(metric telegraf.Metric, key, value string) {
+	if c.tag != nil && c.tag.Match(key) {
+		dest := key
+		if c.Dest != "" {
+			dest = c.Dest
+		}
+		metric.AddTag(dest, c.fn(value))
+	}
+
+	if c.tagKey != nil && c.tagKey.Match(key) {
+		newKey := c.fn(key)
+		if newKey == key {
+			return
+		}
+		// Re-read the value since a tag operation above may have changed it.
+		value, _ = metric.GetTag(key)
+		metric.RemoveTag(key)
+		metric.AddTag(newKey, value)
+	}
+}
+
+func (c *converter) convertField(metric telegraf.Metric, key string, value interface{}) {
+	if c.field != nil && c.field.Match(key) {
+		if sv, ok := value.(string); ok {
+			dest := key
+			if c.Dest != "" {
+				dest = c.Dest
+			}
+			metric.AddField(dest, c.fn(sv))
+		}
+	}
+
+	if c.fieldKey != nil && c.fieldKey.Match(key) {
+		newKey := c.fn(key)
+		if newKey == key {
+			return
+		}
+		value, _ = metric.GetField(key)
+		metric.RemoveField(key)
+		metric.AddField(newKey, value)
+	}
+}
+
+func init() {
+	processors.Add("strings", func() telegraf.Processor {
+		return &Strings{}
+	})
+}
@@ -0,0 +1,257 @@
+package strings
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newM1() telegraf.Metric {
+	m1, _ := metric.New("IIS_log",
+		map[string]string{
+			"verb":           "GET",
+			"s-computername": "MIXEDCASE_hostname",
+		},
+		map[string]interface{}{
+			"request":    "/mixed/CASE/paTH/?from=-1D&to=now",
+			"whitespace": "  whitespace\t",
+			"status":     int64(200),
+		},
+		time.Now(),
+	)
+	return m1
+}
+
+func TestFieldConversions(t *testing.T) {
+	tests := []struct {
+		name   string
+		plugin *Strings
+		check  func(t *testing.T, actual telegraf.Metric)
+	}{
+		{
+			name: "Should change existing field to lowercase",
+			plugin: &Strings{
+				Lowercase: []converter{{Field: "request"}},
+			},
+			check: func(t *testing.T, actual telegraf.Metric) {
+				fv, ok := actual.GetField("request")
+				require.True(t, ok)
+				require.Equal(t, "/mixed/case/path/?from=-1d&to=now", fv)
+			},
+		},
+		{
+			name: "Should change existing field to uppercase",
+			plugin: &Strings{
+				Uppercase: []converter{{Field: "request"}},
+			},
+			check: func(t *testing.T, actual telegraf.Metric) {
+				fv, ok := actual.GetField("request")
+				require.True(t, ok)
+				require.Equal(t, "/MIXED/CASE/PATH/?FROM=-1D&TO=NOW", fv)
+			},
+		},
+		{
+			name: "Should add new lowercase field",
+			plugin: &Strings{
+				Lowercase: []converter{{Field: "request", Dest: "lowercase_request"}},
+			},
+			check: func(t *testing.T, actual telegraf.Metric) {
+				fv, ok := actual.GetField("request")
+				require.True(t, ok)
+				require.Equal(t, "/mixed/CASE/paTH/?from=-1D&to=now", fv)
+
+				fv, ok = actual.GetField("lowercase_request")
+				require.True(t, ok)
+				require.Equal(t, "/mixed/case/path/?from=-1d&to=now", fv)
+			},
+		},
+		{
+			name: "Should trim whitespace by default",
+			plugin: &Strings{
+				Trim: []converter{{Field: "whitespace"}},
+			},
+			check: func(t *testing.T, actual telegraf.Metric) {
+				fv, ok := actual.GetField("whitespace")
+				require.True(t, ok)
+				require.Equal(t, "whitespace", fv)
+			},
+		},
+		{
+			name: "Should trim cutset from the left",
+			plugin: &Strings{
+				TrimLeft: []converter{{Field: "request", Cutset: "/mixed"}},
+			},
+			check: func(t *testing.T, actual telegraf.Metric) {
+				fv, ok := actual.GetField("request")
+				require.True(t, ok)
+				require.Equal(t, "CASE/paTH/?from=-1D&to=now", fv)
+			},
+		},
+		{
+			name: "Should trim prefix",
+			plugin: &Strings{
+				TrimPrefix: []converter{{Field: "request", Prefix: "/mixed"}},
+			},
+			check: func(t *testing.T, actual telegraf.Metric) {
+				fv, ok := actual.GetField("request")
+				require.True(t, ok)
+				require.Equal(t, "/CASE/paTH/?from=-1D&to=now", fv)
+			},
+		},
+		{
+			name: "Should trim suffix",
+			plugin: &Strings{
+				TrimSuffix: []converter{{Field: "request", Suffix: "&to=now"}},
+			},
+			check: func(t *testing.T, actual telegraf.Metric) {
+				fv, ok := actual.GetField("request")
+				require.True(t, ok)
+				require.Equal(t, "/mixed/CASE/paTH/?from=-1D", fv)
+			},
+		},
+		{
+			name: "Should replace all occurrences",
+			plugin: &Strings{
+				Replace: []converter{{Field: "request", Old: "/", New: "_"}},
+			},
+			check: func(t *testing.T, actual telegraf.Metric) {
+				fv, ok := actual.GetField("request")
+				require.True(t, ok)
+				require.Equal(t, "_mixed_CASE_paTH_?from=-1D&to=now", fv)
+			},
+		},
+		{
+			name: "Should keep the leftmost bytes",
+			plugin: &Strings{
+				Left: []converter{{Field: "request", Width: 6}},
+			},
+			check: func(t *testing.T, actual telegraf.Metric) {
+				fv, ok := actual.GetField("request")
+				require.True(t, ok)
+				require.Equal(t, "/mixed", fv)
+			},
+		},
+		{
+			name: "Should not touch non-string fields",
+			plugin: &Strings{
+				Uppercase: []converter{{Field: "*"}},
+			},
+			check: func(t *testing.T, actual telegraf.Metric) {
+				fv, ok := actual.GetField("status")
+				require.True(t, ok)
+				require.Equal(t, int64(200), fv)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := tt.plugin.Apply(newM1())
+			require.Len(t, metrics, 1)
+			tt.check(t, metrics[0])
+		})
+	}
+}
+
+func TestFieldKeyConversions(t *testing.T) {
+	plugin := &Strings{
+		Uppercase: []converter{{FieldKey: "req*"}},
+	}
+
+	metrics := plugin.Apply(newM1())
+	require.Len(t, metrics, 1)
+
+	assert.False(t, metrics[0].HasField("request"))
+	fv, ok := metrics[0].GetField("REQUEST")
+	require.True(t, ok)
+	assert.Equal(t, "/mixed/CASE/paTH/?from=-1D&to=now", fv)
+	assert.True(t, metrics[0].HasField("whitespace"))
+}
+
+func TestTagConversions(t *testing.T) {
+	plugin := &Strings{
+		Lowercase: []converter{{Tag: "s-computername"}},
+		Replace:   []converter{{TagKey: "s-*", Old: "-", New: "_"}},
+	}
+
+	metrics := plugin.Apply(newM1())
+	require.Len(t, metrics, 1)
+
+	assert.Equal(t, map[string]string{
+		"verb":           "GET",
+		"s_computername": "mixedcase_hostname",
+	}, metrics[0].Tags())
+}
+
+func TestMeasurementConversions(t *testing.T) {
+	plugin := &Strings{
+		Lowercase:  []converter{{Measurement: "*"}},
+		TrimSuffix: []converter{{Measurement: "iis*", Suffix: "_log"}},
+	}
+
+	metrics := plugin.Apply(newM1())
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "iis", metrics[0].Name())
+}
+
+func TestBase64Decode(t *testing.T) {
+	m, _ := metric.New("test",
+		map[string]string{},
+		map[string]interface{}{
+			"encoded": "aG93ZHk=",
+			"invalid": "not base64!",
+		},
+		time.Now(),
+	)
+
+	plugin := &Strings{
+		Base64Decode: []converter{{Field: "*"}},
+	}
+
+	metrics := plugin.Apply(m)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, map[string]interface{}{
+		"encoded": "howdy",
+		"invalid": "not base64!",
+	}, metrics[0].Fields())
+}
+
+func TestMultipleConversions(t *testing.T) {
+	plugin := &Strings{
+		Lowercase:  []converter{{Tag: "s-computername"}, {Field: "request"}},
+		Uppercase:  []converter{{Tag: "verb"}},
+		Trim:       []converter{{Field: "whitespace"}},
+		TrimPrefix: []converter{{Tag: "s-computername", Prefix: "mixedcase_"}},
+	}
+
+	metrics := plugin.Apply(newM1())
+	require.Len(t, metrics, 1)
+
+	assert.Equal(t, map[string]string{
+		"verb":           "GET",
+		"s-computername": "hostname",
+	}, metrics[0].Tags())
+	assert.Equal(t, map[string]interface{}{
+		"request":    "/mixed/case/path/?from=-1d&to=now",
+		"whitespace": "whitespace",
+		"status":     int64(200),
+	}, metrics[0].Fields())
+}
+
+func TestNoSelectorIsError(t *testing.T) {
+	plugin := &Strings{
+		Lowercase: []converter{{Dest: "foo"}},
+	}
+	require.Error(t, plugin.compile())
+}
+
+func TestLeftWithoutWidthIsError(t *testing.T) {
+	plugin := &Strings{
+		Left: []converter{{Field: "request"}},
+	}
+	require.Error(t, plugin.compile())
+}
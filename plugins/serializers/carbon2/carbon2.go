@@ -0,0 +1,84 @@
+package carbon2
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+var sanitizer = strings.NewReplacer(" ", "_", "=", "_")
+
+type serializer struct {
+}
+
+func NewSerializer() (*serializer, error) {
+	s := &serializer{}
+	return s, nil
+}
+
+// Serialize writes a line per numeric field of the metric, with the metric
+// and field intrinsic tags and the metric tags as meta tags:
+//
+//	metric=cpu field=usage_idle  cpu=cpu0 91.5 1455320660
+func (s *serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	var buf bytes.Buffer
+	s.write(&buf, metric)
+	return buf.Bytes(), nil
+}
+
+func (s *serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, metric := range metrics {
+		s.write(&buf, metric)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *serializer) write(buf *bytes.Buffer, metric telegraf.Metric) {
+	timestamp := strconv.FormatInt(metric.Time().Unix(), 10)
+	for _, field := range metric.FieldList() {
+		value, ok := formatValue(field.Value)
+		if !ok {
+			continue
+		}
+
+		buf.WriteString("metric=")
+		buf.WriteString(sanitizer.Replace(metric.Name()))
+		buf.WriteString(" field=")
+		buf.WriteString(sanitizer.Replace(field.Key))
+		buf.WriteString(" ")
+		for _, tag := range metric.TagList() {
+			buf.WriteString(" ")
+			buf.WriteString(sanitizer.Replace(tag.Key))
+			buf.WriteString("=")
+			buf.WriteString(sanitizer.Replace(tag.Value))
+		}
+		buf.WriteString(" ")
+		buf.WriteString(value)
+		buf.WriteString(" ")
+		buf.WriteString(timestamp)
+		buf.WriteString("\n")
+	}
+}
+
+// formatValue formats numeric and boolean field values, strings are not
+// supported by Carbon2.
+func formatValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		if v {
+			return "1", true
+		}
+		return "0", true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,90 @@
+package carbon2
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestSerialize(t *testing.T) {
+	now := time.Unix(1455320660, 0)
+	tests := []struct {
+		name     string
+		metric   telegraf.Metric
+		expected string
+	}{
+		{
+			name: "float with tags",
+			metric: testutil.MustMetric(
+				"cpu",
+				map[string]string{"cpu": "cpu0"},
+				map[string]interface{}{"usage_idle": 91.5},
+				now,
+			),
+			expected: "metric=cpu field=usage_idle  cpu=cpu0 91.5 1455320660\n",
+		},
+		{
+			name: "no tags",
+			metric: testutil.MustMetric(
+				"mem",
+				map[string]string{},
+				map[string]interface{}{"used": int64(42)},
+				now,
+			),
+			expected: "metric=mem field=used  42 1455320660\n",
+		},
+		{
+			name: "field per line, strings skipped",
+			metric: testutil.MustMetric(
+				"disk",
+				map[string]string{"path": "/my disk"},
+				map[string]interface{}{
+					"free":   uint64(10),
+					"mode":   "rw",
+					"online": true,
+				},
+				now,
+			),
+			expected: "metric=disk field=free  path=/my_disk 10 1455320660\n" +
+				"metric=disk field=online  path=/my_disk 1 1455320660\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSerializer()
+			require.NoError(t, err)
+			buf, err := s.Serialize(tt.metric)
+			require.NoError(t, err)
+			assert.Equal(t, sortLines(tt.expected), sortLines(string(buf)))
+		})
+	}
+}
+
+// sortLines sorts the lines of s, as the order of fields is not defined.
+func sortLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	sort.Strings(lines)
+	return lines
+}
+
+func TestSerializeBatch(t *testing.T) {
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{"value": int64(42)},
+		time.Unix(0, 0),
+	)
+
+	s, err := NewSerializer()
+	require.NoError(t, err)
+	buf, err := s.SerializeBatch([]telegraf.Metric{m, m})
+	require.NoError(t, err)
+	assert.Equal(t, "metric=cpu field=value  42 0\nmetric=cpu field=value  42 0\n", string(buf))
+}
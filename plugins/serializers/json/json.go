@@ -7,8 +7,19 @@ import (
 	"github.com/influxdata/telegraf"
 )
 
+// BatchFormat is the layout of a batch of metrics.
+type BatchFormat int
+
+const (
+	// BatchObject wraps the metrics in an object with a metrics array.
+	BatchObject BatchFormat = iota
+	// BatchArray writes the metrics as an array.
+	BatchArray
+)
+
 type serializer struct {
 	TimestampUnits time.Duration
+	BatchFormat    BatchFormat
 }
 
 func NewSerializer(timestampUnits time.Duration) (*serializer, error) {
@@ -18,6 +29,11 @@ func NewSerializer(timestampUnits time.Duration) (*serializer, error) {
 	return s, nil
 }
 
+// SetBatchFormat sets the layout used by SerializeBatch.
+func (s *serializer) SetBatchFormat(format BatchFormat) {
+	s.BatchFormat = format
+}
+
 func (s *serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 	m := s.createObject(metric)
 	serialized, err := json.Marshal(m)
@@ -36,8 +52,11 @@ func (s *serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 		objects = append(objects, m)
 	}
 
-	obj := map[string]interface{}{
-		"metrics": objects,
+	var obj interface{} = objects
+	if s.BatchFormat == BatchObject {
+		obj = map[string]interface{}{
+			"metrics": objects,
+		}
 	}
 
 	serialized, err := json.Marshal(obj)
@@ -0,0 +1,149 @@
+package msgpack
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// timestampExt is the MessagePack extension type of timestamps.
+const timestampExt = -1
+
+// appendValue encodes a field value; values of unsupported types are encoded
+// as nil.
+func appendValue(buf []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case int64:
+		return appendInt(buf, v)
+	case uint64:
+		return appendUint(buf, v)
+	case float64:
+		return appendFloat(buf, v)
+	case string:
+		return appendString(buf, v)
+	case bool:
+		return appendBool(buf, v)
+	default:
+		return append(buf, 0xc0)
+	}
+}
+
+func appendBool(buf []byte, v bool) []byte {
+	if v {
+		return append(buf, 0xc3)
+	}
+	return append(buf, 0xc2)
+}
+
+func appendInt(buf []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return appendUint(buf, uint64(v))
+	case v >= -32:
+		return append(buf, byte(v))
+	case v >= math.MinInt8:
+		return append(buf, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		return append(buf, 0xd1, byte(v>>8), byte(v))
+	case v >= math.MinInt32:
+		buf = append(buf, 0xd2)
+		return appendUint32(buf, uint32(v))
+	default:
+		buf = append(buf, 0xd3)
+		return appendUint64(buf, uint64(v))
+	}
+}
+
+func appendUint(buf []byte, v uint64) []byte {
+	switch {
+	case v <= 0x7f:
+		return append(buf, byte(v))
+	case v <= math.MaxUint8:
+		return append(buf, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return append(buf, 0xcd, byte(v>>8), byte(v))
+	case v <= math.MaxUint32:
+		buf = append(buf, 0xce)
+		return appendUint32(buf, uint32(v))
+	default:
+		buf = append(buf, 0xcf)
+		return appendUint64(buf, v)
+	}
+}
+
+func appendFloat(buf []byte, v float64) []byte {
+	buf = append(buf, 0xcb)
+	return appendUint64(buf, math.Float64bits(v))
+}
+
+func appendString(buf []byte, v string) []byte {
+	n := len(v)
+	switch {
+	case n < 32:
+		buf = append(buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		buf = append(buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		buf = append(buf, 0xda, byte(n>>8), byte(n))
+	default:
+		buf = append(buf, 0xdb)
+		buf = appendUint32(buf, uint32(n))
+	}
+	return append(buf, v...)
+}
+
+func appendArrayHeader(buf []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return append(buf, 0xdc, byte(n>>8), byte(n))
+	default:
+		buf = append(buf, 0xdd)
+		return appendUint32(buf, uint32(n))
+	}
+}
+
+func appendMapHeader(buf []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return append(buf, 0xde, byte(n>>8), byte(n))
+	default:
+		buf = append(buf, 0xdf)
+		return appendUint32(buf, uint32(n))
+	}
+}
+
+// appendTime encodes t with the timestamp extension, using the 32, 64 or 96
+// bit format depending on the range and precision of t.
+func appendTime(buf []byte, t time.Time) []byte {
+	sec := t.Unix()
+	nsec := int64(t.Nanosecond())
+
+	if uint64(sec)>>34 == 0 {
+		if nsec == 0 && sec <= math.MaxUint32 {
+			buf = append(buf, 0xd6, byte(timestampExt&0xff))
+			return appendUint32(buf, uint32(sec))
+		}
+		buf = append(buf, 0xd7, byte(timestampExt&0xff))
+		return appendUint64(buf, uint64(nsec)<<34|uint64(sec))
+	}
+
+	buf = append(buf, 0xc7, 12, byte(timestampExt&0xff))
+	buf = appendUint32(buf, uint32(nsec))
+	return appendUint64(buf, uint64(sec))
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	return append(buf, b[:]...)
+}
+
+func appendUint64(buf []byte, v uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	return append(buf, b[:]...)
+}
@@ -0,0 +1,58 @@
+package msgpack
+
+import (
+	"github.com/influxdata/telegraf"
+)
+
+// serializer encodes metrics as MessagePack maps with the same layout as the
+// json serializer, using the MessagePack timestamp extension for the time.
+type serializer struct {
+}
+
+func NewSerializer() (*serializer, error) {
+	s := &serializer{}
+	return s, nil
+}
+
+func (s *serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return appendMetric(nil, metric), nil
+}
+
+func (s *serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	buf := appendMapHeader(nil, 1)
+	buf = appendString(buf, "metrics")
+	buf = appendArrayHeader(buf, len(metrics))
+	for _, metric := range metrics {
+		buf = appendMetric(buf, metric)
+	}
+	return buf, nil
+}
+
+// appendMetric encodes the metric as a map with the fields, name, tags and
+// timestamp keys.
+func appendMetric(buf []byte, metric telegraf.Metric) []byte {
+	buf = appendMapHeader(buf, 4)
+
+	fields := metric.FieldList()
+	buf = appendString(buf, "fields")
+	buf = appendMapHeader(buf, len(fields))
+	for _, field := range fields {
+		buf = appendString(buf, field.Key)
+		buf = appendValue(buf, field.Value)
+	}
+
+	buf = appendString(buf, "name")
+	buf = appendString(buf, metric.Name())
+
+	tags := metric.TagList()
+	buf = appendString(buf, "tags")
+	buf = appendMapHeader(buf, len(tags))
+	for _, tag := range tags {
+		buf = appendString(buf, tag.Key)
+		buf = appendString(buf, tag.Value)
+	}
+
+	buf = appendString(buf, "timestamp")
+	buf = appendTime(buf, metric.Time())
+	return buf
+}
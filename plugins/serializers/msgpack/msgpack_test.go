@@ -0,0 +1,84 @@
+package msgpack
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/parsers/msgpack"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestSerialize(t *testing.T) {
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"value": int64(42)},
+		time.Unix(1532459904, 0),
+	)
+
+	s, err := NewSerializer()
+	require.NoError(t, err)
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	expected := []byte{
+		0x84,
+		0xa6, 'f', 'i', 'e', 'l', 'd', 's', 0x81, 0xa5, 'v', 'a', 'l', 'u', 'e', 0x2a,
+		0xa4, 'n', 'a', 'm', 'e', 0xa3, 'c', 'p', 'u',
+		0xa4, 't', 'a', 'g', 's', 0x81, 0xa4, 'h', 'o', 's', 't', 0xa1, 'a',
+		0xa9, 't', 'i', 'm', 'e', 's', 't', 'a', 'm', 'p', 0xd6, 0xff, 0x5b, 0x57, 0x7b, 0x80,
+	}
+	assert.Equal(t, expected, buf)
+}
+
+func TestSerializeRoundTrip(t *testing.T) {
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": "a", "cpu": "cpu0"},
+			map[string]interface{}{
+				"int":    int64(-1 << 40),
+				"small":  int64(-20),
+				"uint":   uint64(math.MaxUint64),
+				"float":  91.5,
+				"bool":   true,
+				"string": "a string that is longer than thirty-two bytes",
+			},
+			time.Unix(1532459904, 123456789),
+		),
+		testutil.MustMetric(
+			"mem",
+			map[string]string{},
+			map[string]interface{}{"used": int64(1 << 20)},
+			time.Unix(1<<35, 1),
+		),
+	}
+
+	s, err := NewSerializer()
+	require.NoError(t, err)
+	p := msgpack.NewParser("", nil)
+
+	for _, m := range metrics {
+		buf, err := s.Serialize(m)
+		require.NoError(t, err)
+		parsed, err := p.ParseLine(string(buf))
+		require.NoError(t, err)
+		assert.Equal(t, m.Name(), parsed.Name())
+		assert.Equal(t, m.Tags(), parsed.Tags())
+		assert.Equal(t, m.Fields(), parsed.Fields())
+		assert.Equal(t, m.Time().UnixNano(), parsed.Time().UnixNano())
+	}
+
+	buf, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+	parsed, err := p.Parse(buf)
+	require.NoError(t, err)
+	require.Len(t, parsed, 2)
+	assert.Equal(t, "cpu", parsed[0].Name())
+	assert.Equal(t, "mem", parsed[1].Name())
+}
@@ -0,0 +1,313 @@
+package prometheus
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/influxdata/telegraf"
+	parser "github.com/influxdata/telegraf/plugins/parsers/prometheus"
+)
+
+const helpString = "Telegraf collected metric"
+
+var (
+	invalidNameCharRE  = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+	invalidLabelCharRE = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+)
+
+// FormatConfig holds the options of the Prometheus serializer.
+type FormatConfig struct {
+	// ExportTimestamp adds the metric timestamp to each sample.
+	ExportTimestamp bool
+	// StringAsLabel adds string fields as labels of the samples of the
+	// metric, by default they are dropped.
+	StringAsLabel bool
+}
+
+// Serializer renders metrics in the Prometheus text exposition format.
+type Serializer struct {
+	config FormatConfig
+}
+
+// NewSerializer returns a Prometheus serializer.
+func NewSerializer(config FormatConfig) (*Serializer, error) {
+	s := &Serializer{config: config}
+	return s, nil
+}
+
+// Serialize renders a single metric, including the HELP and TYPE comments of
+// its metric families.
+func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return s.SerializeBatch([]telegraf.Metric{metric})
+}
+
+// SerializeBatch renders metrics grouped by metric family, each family is
+// written once with a single HELP and TYPE comment.  If a series appears
+// more than once the latest sample is kept.
+func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	families := make(map[string]*family)
+	for _, metric := range metrics {
+		s.add(families, metric)
+	}
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		_, err := expfmt.MetricFamilyToText(&buf, families[name].metricFamily(name))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// family collects the samples of one metric family by series.
+type family struct {
+	metricType dto.MetricType
+	series     map[string]*dto.Metric
+}
+
+func (f *family) metricFamily(name string) *dto.MetricFamily {
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	mf := &dto.MetricFamily{
+		Name: proto.String(name),
+		Help: proto.String(helpString),
+		Type: f.metricType.Enum(),
+	}
+	for _, key := range keys {
+		mf.Metric = append(mf.Metric, f.series[key])
+	}
+	return mf
+}
+
+func (s *Serializer) add(families map[string]*family, metric telegraf.Metric) {
+	labelSet := Labels(metric, s.config.StringAsLabel)
+	key := SeriesKey(labelSet)
+	labels := labelPairs(labelSet)
+	metricType := parser.MetricType(metric.Type())
+
+	var timestamp *int64
+	if s.config.ExportTimestamp {
+		timestamp = proto.Int64(metric.Time().UnixNano() / 1000000)
+	}
+
+	switch metricType {
+	case dto.MetricType_SUMMARY:
+		summary := &dto.Summary{}
+		for _, field := range metric.FieldList() {
+			value, ok := FloatValue(field.Value)
+			if !ok {
+				continue
+			}
+			switch field.Key {
+			case "count":
+				summary.SampleCount = proto.Uint64(uint64(value))
+			case "sum":
+				summary.SampleSum = proto.Float64(value)
+			default:
+				quantile, err := strconv.ParseFloat(field.Key, 64)
+				if err != nil {
+					continue
+				}
+				summary.Quantile = append(summary.Quantile, &dto.Quantile{
+					Quantile: proto.Float64(quantile),
+					Value:    proto.Float64(value),
+				})
+			}
+		}
+		sort.Slice(summary.Quantile, func(i, j int) bool {
+			return summary.Quantile[i].GetQuantile() < summary.Quantile[j].GetQuantile()
+		})
+		s.addSample(families, SanitizeName(metric.Name()), metricType, key, &dto.Metric{
+			Label:       labels,
+			Summary:     summary,
+			TimestampMs: timestamp,
+		})
+	case dto.MetricType_HISTOGRAM:
+		histogram := &dto.Histogram{}
+		for _, field := range metric.FieldList() {
+			value, ok := FloatValue(field.Value)
+			if !ok {
+				continue
+			}
+			switch field.Key {
+			case "count":
+				histogram.SampleCount = proto.Uint64(uint64(value))
+			case "sum":
+				histogram.SampleSum = proto.Float64(value)
+			default:
+				bound, err := strconv.ParseFloat(field.Key, 64)
+				if err != nil {
+					continue
+				}
+				histogram.Bucket = append(histogram.Bucket, &dto.Bucket{
+					UpperBound:      proto.Float64(bound),
+					CumulativeCount: proto.Uint64(uint64(value)),
+				})
+			}
+		}
+		sort.Slice(histogram.Bucket, func(i, j int) bool {
+			return histogram.Bucket[i].GetUpperBound() < histogram.Bucket[j].GetUpperBound()
+		})
+		s.addSample(families, SanitizeName(metric.Name()), metricType, key, &dto.Metric{
+			Label:       labels,
+			Histogram:   histogram,
+			TimestampMs: timestamp,
+		})
+	default:
+		for _, field := range metric.FieldList() {
+			value, ok := FloatValue(field.Value)
+			if !ok {
+				continue
+			}
+
+			// The value field of each type is named after the metric, as
+			// created by the prometheus parser.
+			name := metric.Name()
+			if field.Key != parser.ValueFieldName(metricType) && field.Key != "value" {
+				name = name + "_" + field.Key
+			}
+
+			sample := &dto.Metric{
+				Label:       labels,
+				TimestampMs: timestamp,
+			}
+			switch metricType {
+			case dto.MetricType_COUNTER:
+				sample.Counter = &dto.Counter{Value: proto.Float64(value)}
+			case dto.MetricType_GAUGE:
+				sample.Gauge = &dto.Gauge{Value: proto.Float64(value)}
+			default:
+				sample.Untyped = &dto.Untyped{Value: proto.Float64(value)}
+			}
+			s.addSample(families, SanitizeName(name), metricType, key, sample)
+		}
+	}
+}
+
+// addSample adds the sample to its family, samples conflicting with the type
+// of the family are dropped.
+func (s *Serializer) addSample(families map[string]*family, name string, metricType dto.MetricType, key string, sample *dto.Metric) {
+	f, ok := families[name]
+	if !ok {
+		f = &family{
+			metricType: metricType,
+			series:     make(map[string]*dto.Metric),
+		}
+		families[name] = f
+	}
+	if f.metricType != metricType {
+		return
+	}
+
+	if prev, ok := f.series[key]; ok && prev.GetTimestampMs() > sample.GetTimestampMs() {
+		return
+	}
+	f.series[key] = sample
+}
+
+func labelPairs(labels map[string]string) []*dto.LabelPair {
+	pairs := make([]*dto.LabelPair, 0, len(labels))
+	for name, value := range labels {
+		pairs = append(pairs, &dto.LabelPair{
+			Name:  proto.String(name),
+			Value: proto.String(value),
+		})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].GetName() < pairs[j].GetName()
+	})
+	return pairs
+}
+
+// Labels returns the sanitized labels of the metric, with the string fields
+// added if stringAsLabel is set.
+func Labels(metric telegraf.Metric, stringAsLabel bool) map[string]string {
+	labels := make(map[string]string)
+	for _, tag := range metric.TagList() {
+		labels[SanitizeLabel(tag.Key)] = tag.Value
+	}
+
+	// Prometheus doesn't have a string value type, so convert string
+	// fields to labels if enabled.
+	if stringAsLabel {
+		for _, field := range metric.FieldList() {
+			if value, ok := field.Value.(string); ok {
+				labels[SanitizeLabel(field.Key)] = value
+			}
+		}
+	}
+	delete(labels, "")
+	return labels
+}
+
+// SeriesKey returns a key identifying the series of the labels.
+func SeriesKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+"="+labels[name])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// FloatValue returns the numeric field value as a float, string and bool
+// values are ignored.
+func FloatValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		if math.IsNaN(v) {
+			return 0, false
+		}
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// SanitizeName replaces the characters not allowed in Prometheus metric
+// names with underscores.
+func SanitizeName(name string) string {
+	name = invalidNameCharRE.ReplaceAllString(name, "_")
+	if len(name) > 0 && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// SanitizeLabel replaces the characters not allowed in Prometheus label
+// names with underscores.
+func SanitizeLabel(name string) string {
+	name = invalidLabelCharRE.ReplaceAllString(name, "_")
+	if len(name) > 0 && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
@@ -0,0 +1,183 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	parser "github.com/influxdata/telegraf/plugins/parsers/prometheus"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestSerializeGauge(t *testing.T) {
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{"host": "example.org"},
+		map[string]interface{}{"time_idle": 42.0},
+		time.Unix(0, 0),
+		telegraf.Gauge,
+	)
+
+	s, err := NewSerializer(FormatConfig{})
+	require.NoError(t, err)
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	expected := `# HELP cpu_time_idle Telegraf collected metric
+# TYPE cpu_time_idle gauge
+cpu_time_idle{host="example.org"} 42
+`
+	assert.Equal(t, expected, string(buf))
+}
+
+func TestSerializeValueFieldAndTimestamp(t *testing.T) {
+	m := testutil.MustMetric(
+		"http_requests_total",
+		map[string]string{"code": "200"},
+		map[string]interface{}{"counter": 1027.0, "message": "ok"},
+		time.Unix(1395066363, 0),
+		telegraf.Counter,
+	)
+
+	s, err := NewSerializer(FormatConfig{ExportTimestamp: true, StringAsLabel: true})
+	require.NoError(t, err)
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	expected := `# HELP http_requests_total Telegraf collected metric
+# TYPE http_requests_total counter
+http_requests_total{code="200",message="ok"} 1027 1395066363000
+`
+	assert.Equal(t, expected, string(buf))
+}
+
+func TestSerializeBatchGroupsFamilies(t *testing.T) {
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"cpu": "cpu1"},
+			map[string]interface{}{"usage_idle": 90.0, "active": true},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 80.0},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 85.0},
+			time.Unix(10, 0),
+		),
+		testutil.MustMetric(
+			"disk.io",
+			map[string]string{"device-name": "sda"},
+			map[string]interface{}{"value": int64(3)},
+			time.Unix(0, 0),
+		),
+	}
+
+	s, err := NewSerializer(FormatConfig{})
+	require.NoError(t, err)
+	buf, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	expected := `# HELP cpu_usage_idle Telegraf collected metric
+# TYPE cpu_usage_idle untyped
+cpu_usage_idle{cpu="cpu0"} 85
+cpu_usage_idle{cpu="cpu1"} 90
+# HELP disk_io Telegraf collected metric
+# TYPE disk_io untyped
+disk_io{device_name="sda"} 3
+`
+	assert.Equal(t, expected, string(buf))
+}
+
+func TestSerializeSummaryAndHistogram(t *testing.T) {
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"rpc_duration_seconds",
+			map[string]string{},
+			map[string]interface{}{
+				"0.5":   0.05,
+				"0.01":  0.001,
+				"count": 10.0,
+				"sum":   1.5,
+			},
+			time.Unix(0, 0),
+			telegraf.Summary,
+		),
+		testutil.MustMetric(
+			"request_latency",
+			map[string]string{"verb": "POST"},
+			map[string]interface{}{
+				"0.5":   2.0,
+				"1":     3.0,
+				"+Inf":  4.0,
+				"count": 4.0,
+				"sum":   5.5,
+			},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+	}
+
+	s, err := NewSerializer(FormatConfig{})
+	require.NoError(t, err)
+	buf, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	expected := `# HELP request_latency Telegraf collected metric
+# TYPE request_latency histogram
+request_latency_bucket{verb="POST",le="0.5"} 2
+request_latency_bucket{verb="POST",le="1"} 3
+request_latency_bucket{verb="POST",le="+Inf"} 4
+request_latency_sum{verb="POST"} 5.5
+request_latency_count{verb="POST"} 4
+# HELP rpc_duration_seconds Telegraf collected metric
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.01"} 0.001
+rpc_duration_seconds{quantile="0.5"} 0.05
+rpc_duration_seconds_sum 1.5
+rpc_duration_seconds_count 10
+`
+	assert.Equal(t, expected, string(buf))
+}
+
+func TestSerializeParseRoundTrip(t *testing.T) {
+	input := `# HELP http_request_duration_microseconds The HTTP request latencies in microseconds.
+# TYPE http_request_duration_microseconds summary
+http_request_duration_microseconds{handler="prometheus",quantile="0.5"} 552048.506
+http_request_duration_microseconds{handler="prometheus",quantile="0.9"} 5.876804288e+06
+http_request_duration_microseconds_sum{handler="prometheus"} 1.8909097205e+07
+http_request_duration_microseconds_count{handler="prometheus"} 9
+# HELP get_token_fail_count Counter of failed Token() requests
+# TYPE get_token_fail_count counter
+get_token_fail_count 3
+`
+	p := &parser.Parser{}
+	metrics, err := p.Parse([]byte(input))
+	require.NoError(t, err)
+
+	s, err := NewSerializer(FormatConfig{})
+	require.NoError(t, err)
+	buf, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	expected := `# HELP get_token_fail_count Telegraf collected metric
+# TYPE get_token_fail_count counter
+get_token_fail_count 3
+# HELP http_request_duration_microseconds Telegraf collected metric
+# TYPE http_request_duration_microseconds summary
+http_request_duration_microseconds{handler="prometheus",quantile="0.5"} 552048.506
+http_request_duration_microseconds{handler="prometheus",quantile="0.9"} 5.876804288e+06
+http_request_duration_microseconds_sum{handler="prometheus"} 1.8909097205e+07
+http_request_duration_microseconds_count{handler="prometheus"} 9
+`
+	assert.Equal(t, expected, string(buf))
+}
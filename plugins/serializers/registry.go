@@ -6,9 +6,14 @@ import (
 
 	"github.com/influxdata/telegraf"
 
+	"github.com/influxdata/telegraf/plugins/serializers/carbon2"
 	"github.com/influxdata/telegraf/plugins/serializers/graphite"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/plugins/serializers/json"
+	"github.com/influxdata/telegraf/plugins/serializers/msgpack"
+	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
+	"github.com/influxdata/telegraf/plugins/serializers/splunkmetric"
+	"github.com/influxdata/telegraf/plugins/serializers/template"
 )
 
 // SerializerOutput is an interface for output plugins that are able to
@@ -35,7 +40,8 @@ type Serializer interface {
 // Config is a struct that covers the data types needed for all serializer types,
 // and can be used to instantiate _any_ of the serializers.
 type Config struct {
-	// Dataformat can be one of: influx, graphite, or json
+	// Dataformat can be one of: influx, graphite, json, prometheus, msgpack,
+	// splunkmetric, carbon2 or template
 	DataFormat string
 
 	// Support tags in graphite protocol
@@ -60,6 +66,32 @@ type Config struct {
 
 	// Timestamp units to use for JSON formatted output
 	TimestampUnits time.Duration
+
+	// Layout of a batch of metrics, either "object" or "array"; JSON format
+	// only
+	JSONBatchFormat string
+
+	// Include the metric timestamp on each sample; prometheus format only
+	PrometheusExportTimestamp bool
+
+	// Add string fields as labels; prometheus format only
+	PrometheusStringAsLabel bool
+
+	// Wrap metrics in HEC events; splunkmetric format only
+	SplunkmetricHecRouting bool
+
+	// Write all fields of a metric in a single event; splunkmetric format
+	// only
+	SplunkmetricMultiMetric bool
+
+	// Go template rendered for each metric, or each field if
+	// TemplateSplitFields is set; template format only
+	TemplateFormat      string
+	TemplateSplitFields bool
+
+	// Go templates rendered before and after a batch; template format only
+	TemplateBatchHeader string
+	TemplateBatchFooter string
 }
 
 // NewSerializer a Serializer interface based on the given config.
@@ -72,7 +104,20 @@ func NewSerializer(config *Config) (Serializer, error) {
 	case "graphite":
 		serializer, err = NewGraphiteSerializer(config.Prefix, config.Template, config.GraphiteTagSupport)
 	case "json":
-		serializer, err = NewJsonSerializer(config.TimestampUnits)
+		serializer, err = NewJsonSerializerConfig(config)
+	case "prometheus":
+		serializer, err = NewPrometheusSerializer(config)
+	case "msgpack":
+		serializer, err = NewMsgpackSerializer()
+	case "splunkmetric":
+		serializer, err = NewSplunkmetricSerializer(config.SplunkmetricHecRouting, config.SplunkmetricMultiMetric)
+	case "carbon2":
+		serializer, err = NewCarbon2Serializer()
+	case "template":
+		serializer, err = NewTemplateSerializer(config.TemplateFormat,
+			config.TemplateSplitFields,
+			config.TemplateBatchHeader,
+			config.TemplateBatchFooter)
 	default:
 		err = fmt.Errorf("Invalid data format: %s", config.DataFormat)
 	}
@@ -83,6 +128,48 @@ func NewJsonSerializer(timestampUnits time.Duration) (Serializer, error) {
 	return json.NewSerializer(timestampUnits)
 }
 
+func NewJsonSerializerConfig(config *Config) (Serializer, error) {
+	var format json.BatchFormat
+	switch config.JSONBatchFormat {
+	case "", "object":
+		format = json.BatchObject
+	case "array":
+		format = json.BatchArray
+	default:
+		return nil, fmt.Errorf("invalid json_batch_format: %s", config.JSONBatchFormat)
+	}
+
+	s, err := json.NewSerializer(config.TimestampUnits)
+	if err != nil {
+		return nil, err
+	}
+	s.SetBatchFormat(format)
+	return s, nil
+}
+
+func NewSplunkmetricSerializer(hecRouting bool, multiMetric bool) (Serializer, error) {
+	return splunkmetric.NewSerializer(hecRouting, multiMetric)
+}
+
+func NewCarbon2Serializer() (Serializer, error) {
+	return carbon2.NewSerializer()
+}
+
+func NewTemplateSerializer(format string, splitFields bool, header string, footer string) (Serializer, error) {
+	return template.NewSerializer(format, splitFields, header, footer)
+}
+
+func NewMsgpackSerializer() (Serializer, error) {
+	return msgpack.NewSerializer()
+}
+
+func NewPrometheusSerializer(config *Config) (Serializer, error) {
+	return prometheus.NewSerializer(prometheus.FormatConfig{
+		ExportTimestamp: config.PrometheusExportTimestamp,
+		StringAsLabel:   config.PrometheusStringAsLabel,
+	})
+}
+
 func NewInfluxSerializerConfig(config *Config) (Serializer, error) {
 	var sort influx.FieldSortOrder
 	if config.InfluxSortFields {
@@ -0,0 +1,135 @@
+package splunkmetric
+
+import (
+	"encoding/json"
+
+	"github.com/influxdata/telegraf"
+)
+
+// hecEvent is a metric event of the Splunk HTTP Event Collector.
+type hecEvent struct {
+	Time   float64                `json:"time"`
+	Event  string                 `json:"event"`
+	Host   string                 `json:"host,omitempty"`
+	Index  string                 `json:"index,omitempty"`
+	Source string                 `json:"source,omitempty"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+type serializer struct {
+	// HecRouting wraps the metrics in HEC events, the host, index and
+	// source tags are used as event metadata instead of dimensions.
+	HecRouting bool
+	// MultiMetric writes all fields of a metric in a single event, this
+	// requires Splunk 8.0 or later.
+	MultiMetric bool
+}
+
+func NewSerializer(hecRouting bool, multiMetric bool) (*serializer, error) {
+	s := &serializer{
+		HecRouting:  hecRouting,
+		MultiMetric: multiMetric,
+	}
+	return s, nil
+}
+
+func (s *serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	var serialized []byte
+	for _, event := range s.createEvents(metric) {
+		var obj interface{} = event
+		if !s.HecRouting {
+			event.Fields["time"] = event.Time
+			obj = event.Fields
+		}
+
+		b, err := json.Marshal(obj)
+		if err != nil {
+			return []byte{}, err
+		}
+		serialized = append(serialized, b...)
+		serialized = append(serialized, '\n')
+	}
+	return serialized, nil
+}
+
+func (s *serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	var serialized []byte
+	for _, metric := range metrics {
+		b, err := s.Serialize(metric)
+		if err != nil {
+			return []byte{}, err
+		}
+		serialized = append(serialized, b...)
+	}
+	return serialized, nil
+}
+
+// createEvents returns one event per numeric field, or a single event with
+// all numeric fields in multi-metric mode.  String fields are skipped.
+func (s *serializer) createEvents(metric telegraf.Metric) []*hecEvent {
+	newEvent := func() *hecEvent {
+		event := &hecEvent{
+			Time:   float64(metric.Time().UnixNano()) / 1e9,
+			Event:  "metric",
+			Fields: make(map[string]interface{}),
+		}
+		for _, tag := range metric.TagList() {
+			if s.HecRouting {
+				switch tag.Key {
+				case "host":
+					event.Host = tag.Value
+					continue
+				case "index":
+					event.Index = tag.Value
+					continue
+				case "source":
+					event.Source = tag.Value
+					continue
+				}
+			}
+			event.Fields[tag.Key] = tag.Value
+		}
+		return event
+	}
+
+	var events []*hecEvent
+	var multi *hecEvent
+	for _, field := range metric.FieldList() {
+		value, ok := metricValue(field.Value)
+		if !ok {
+			continue
+		}
+
+		name := metric.Name() + "." + field.Key
+		if s.MultiMetric {
+			if multi == nil {
+				multi = newEvent()
+				events = append(events, multi)
+			}
+			multi.Fields["metric_name:"+name] = value
+			continue
+		}
+
+		event := newEvent()
+		event.Fields["metric_name"] = name
+		event.Fields["_value"] = value
+		events = append(events, event)
+	}
+	return events
+}
+
+// metricValue returns the field value as a Splunk metric value, booleans are
+// converted to 0 or 1 and strings are not supported.
+func metricValue(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case int64, uint64, float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,100 @@
+package splunkmetric
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func testMetric() telegraf.Metric {
+	return testutil.MustMetric(
+		"cpu",
+		map[string]string{"cpu": "cpu0", "host": "server01"},
+		map[string]interface{}{
+			"usage_idle": 91.5,
+			"usage_user": int64(2),
+			"state":      "ok",
+		},
+		time.Unix(1529708430, 0),
+	)
+}
+
+func TestSerialize(t *testing.T) {
+	tests := []struct {
+		name        string
+		hecRouting  bool
+		multiMetric bool
+		expected    string
+	}{
+		{
+			name: "metric per field",
+			expected: `{"_value":91.5,"cpu":"cpu0","host":"server01","metric_name":"cpu.usage_idle","time":1529708430}` + "\n" +
+				`{"_value":2,"cpu":"cpu0","host":"server01","metric_name":"cpu.usage_user","time":1529708430}` + "\n",
+		},
+		{
+			name:       "hec routing",
+			hecRouting: true,
+			expected: `{"time":1529708430,"event":"metric","host":"server01","fields":{"_value":91.5,"cpu":"cpu0","metric_name":"cpu.usage_idle"}}` + "\n" +
+				`{"time":1529708430,"event":"metric","host":"server01","fields":{"_value":2,"cpu":"cpu0","metric_name":"cpu.usage_user"}}` + "\n",
+		},
+		{
+			name:        "multi metric",
+			multiMetric: true,
+			expected:    `{"cpu":"cpu0","host":"server01","metric_name:cpu.usage_idle":91.5,"metric_name:cpu.usage_user":2,"time":1529708430}` + "\n",
+		},
+		{
+			name:        "multi metric with hec routing",
+			hecRouting:  true,
+			multiMetric: true,
+			expected:    `{"time":1529708430,"event":"metric","host":"server01","fields":{"cpu":"cpu0","metric_name:cpu.usage_idle":91.5,"metric_name:cpu.usage_user":2}}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSerializer(tt.hecRouting, tt.multiMetric)
+			require.NoError(t, err)
+			buf, err := s.Serialize(testMetric())
+			require.NoError(t, err)
+			assert.Equal(t, sortLines(tt.expected), sortLines(string(buf)))
+		})
+	}
+}
+
+// sortLines sorts the lines of s, as the order of fields is not defined.
+func sortLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	sort.Strings(lines)
+	return lines
+}
+
+func TestSerializeBool(t *testing.T) {
+	m := testutil.MustMetric(
+		"docker",
+		map[string]string{},
+		map[string]interface{}{"oomkilled": true},
+		time.Unix(1529708430, 500000000),
+	)
+
+	s, err := NewSerializer(false, false)
+	require.NoError(t, err)
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+	assert.Equal(t, `{"_value":1,"metric_name":"docker.oomkilled","time":1529708430.5}`+"\n", string(buf))
+}
+
+func TestSerializeBatch(t *testing.T) {
+	s, err := NewSerializer(false, true)
+	require.NoError(t, err)
+	buf, err := s.SerializeBatch([]telegraf.Metric{testMetric(), testMetric()})
+	require.NoError(t, err)
+
+	event := `{"cpu":"cpu0","host":"server01","metric_name:cpu.usage_idle":91.5,"metric_name:cpu.usage_user":2,"time":1529708430}` + "\n"
+	assert.Equal(t, event+event, string(buf))
+}
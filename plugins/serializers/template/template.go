@@ -0,0 +1,174 @@
+package template
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// funcs are the helpers available in the templates.
+var funcs = template.FuncMap{
+	"unix": func(t time.Time) int64 {
+		return t.Unix()
+	},
+	"unix_ms": func(t time.Time) int64 {
+		return t.UnixNano() / int64(time.Millisecond)
+	},
+	"unix_us": func(t time.Time) int64 {
+		return t.UnixNano() / int64(time.Microsecond)
+	},
+	"unix_ns": func(t time.Time) int64 {
+		return t.UnixNano()
+	},
+	"time_format": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+}
+
+// Metric is the data of the format template, it is also used by the
+// templates of other plugins rendering values from a metric.
+type Metric struct {
+	metric telegraf.Metric
+}
+
+// NewMetric returns the template data of the metric.
+func NewMetric(metric telegraf.Metric) *Metric {
+	return &Metric{metric: metric}
+}
+
+func (m *Metric) Name() string {
+	return m.metric.Name()
+}
+
+func (m *Metric) Tags() map[string]string {
+	return m.metric.Tags()
+}
+
+// Tag returns the value of the tag, or an empty string if not set.
+func (m *Metric) Tag(key string) string {
+	value, _ := m.metric.GetTag(key)
+	return value
+}
+
+func (m *Metric) Fields() map[string]interface{} {
+	return m.metric.Fields()
+}
+
+// Field returns the value of the field, or nil if not set.
+func (m *Metric) Field(key string) interface{} {
+	value, _ := m.metric.GetField(key)
+	return value
+}
+
+func (m *Metric) Time() time.Time {
+	return m.metric.Time()
+}
+
+// Field is the data of the format template when fields are rendered
+// separately, with the key and value of a single field.
+type Field struct {
+	*Metric
+	Key   string
+	Value interface{}
+}
+
+type serializer struct {
+	format      *template.Template
+	splitFields bool
+	header      *template.Template
+	footer      *template.Template
+}
+
+// NewSerializer returns a serializer rendering each metric, or each field if
+// splitFields is set, with the format template.  The header and footer
+// templates are rendered before and after the metrics of a batch.
+func NewSerializer(format string, splitFields bool, header string, footer string) (*serializer, error) {
+	if format == "" {
+		return nil, fmt.Errorf("template: format is required")
+	}
+
+	s := &serializer{splitFields: splitFields}
+
+	var err error
+	s.format, err = template.New("format").Funcs(funcs).Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("template: parsing format: %v", err)
+	}
+	if header != "" {
+		s.header, err = template.New("header").Funcs(funcs).Parse(header)
+		if err != nil {
+			return nil, fmt.Errorf("template: parsing batch header: %v", err)
+		}
+	}
+	if footer != "" {
+		s.footer, err = template.New("footer").Funcs(funcs).Parse(footer)
+		if err != nil {
+			return nil, fmt.Errorf("template: parsing batch footer: %v", err)
+		}
+	}
+	return s, nil
+}
+
+func (s *serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.write(&buf, NewMetric(metric)); err != nil {
+		return []byte{}, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SerializeBatch renders the metrics between the header and footer, the
+// header and footer templates get the slice of metrics as data.
+func (s *serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	data := make([]*Metric, 0, len(metrics))
+	for _, metric := range metrics {
+		data = append(data, NewMetric(metric))
+	}
+
+	var buf bytes.Buffer
+	if s.header != nil {
+		if err := s.header.Execute(&buf, data); err != nil {
+			return []byte{}, err
+		}
+	}
+	for _, m := range data {
+		if err := s.write(&buf, m); err != nil {
+			return []byte{}, err
+		}
+	}
+	if s.footer != nil {
+		if err := s.footer.Execute(&buf, data); err != nil {
+			return []byte{}, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// write renders the metric, or each of its fields, adding a newline after
+// each rendering unless the template ends with one.
+func (s *serializer) write(buf *bytes.Buffer, m *Metric) error {
+	if !s.splitFields {
+		return s.execute(buf, m)
+	}
+
+	for _, field := range m.metric.FieldList() {
+		err := s.execute(buf, &Field{Metric: m, Key: field.Key, Value: field.Value})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *serializer) execute(buf *bytes.Buffer, data interface{}) error {
+	if err := s.format.Execute(buf, data); err != nil {
+		return err
+	}
+	if b := buf.Bytes(); len(b) == 0 || b[len(b)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	return nil
+}
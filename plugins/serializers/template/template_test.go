@@ -0,0 +1,103 @@
+package template
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func testMetric() telegraf.Metric {
+	return testutil.MustMetric(
+		"cpu",
+		map[string]string{"host": "server01", "cpu": "cpu0"},
+		map[string]interface{}{
+			"usage_idle": 91.5,
+			"usage_user": int64(2),
+		},
+		time.Unix(1529708430, 123000000),
+	)
+}
+
+func TestSerialize(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      string
+		splitFields bool
+		expected    string
+	}{
+		{
+			name:     "metric",
+			format:   `{{.Tag "host"}}|{{.Name}}|{{.Field "usage_idle"}}|{{unix .Time}}`,
+			expected: "server01|cpu|91.5|1529708430\n",
+		},
+		{
+			name:        "field per line",
+			format:      `{{.Tag "host"}}|{{.Name}}.{{.Key}}|{{.Value}}|{{unix_ms .Time}}`,
+			splitFields: true,
+			expected:    "server01|cpu.usage_idle|91.5|1529708430123\nserver01|cpu.usage_user|2|1529708430123\n",
+		},
+		{
+			name:     "ranges and trailing newline",
+			format:   "{{.Name}}{{range $k, $v := .Tags}} {{$k}}={{$v}}{{end}} {{time_format \"2006-01-02\" .Time}}\n",
+			expected: "cpu cpu=cpu0 host=server01 2018-06-22\n",
+		},
+		{
+			name:     "missing tag and field",
+			format:   `{{.Tag "region"}}|{{.Field "missing"}}`,
+			expected: "|<no value>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSerializer(tt.format, tt.splitFields, "", "")
+			require.NoError(t, err)
+			buf, err := s.Serialize(testMetric())
+			require.NoError(t, err)
+			assert.Equal(t, sortLines(tt.expected), sortLines(string(buf)))
+		})
+	}
+}
+
+// sortLines sorts the lines of s, as the order of fields is not defined.
+func sortLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	sort.Strings(lines)
+	return lines
+}
+
+func TestSerializeBatch(t *testing.T) {
+	s, err := NewSerializer(
+		`{{.Name}} {{.Field "usage_user"}}`,
+		false,
+		"# {{len .}} metrics\n",
+		"# end\n",
+	)
+	require.NoError(t, err)
+
+	buf, err := s.SerializeBatch([]telegraf.Metric{testMetric(), testMetric()})
+	require.NoError(t, err)
+	assert.Equal(t, "# 2 metrics\ncpu 2\ncpu 2\n# end\n", string(buf))
+}
+
+func TestInvalidTemplate(t *testing.T) {
+	_, err := NewSerializer("", false, "", "")
+	require.Error(t, err)
+
+	_, err = NewSerializer("{{.Name", false, "", "")
+	require.Error(t, err)
+
+	_, err = NewSerializer("{{.Name}}", false, "{{", "")
+	require.Error(t, err)
+
+	s, err := NewSerializer("{{.Unknown}}", false, "", "")
+	require.NoError(t, err)
+	_, err = s.Serialize(testMetric())
+	require.Error(t, err)
+}
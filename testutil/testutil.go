@@ -41,6 +41,21 @@ func MockMetrics() []telegraf.Metric {
 	return metrics
 }
 
+// MustMetric returns a new metric, panicking if it cannot be created.
+func MustMetric(
+	name string,
+	tags map[string]string,
+	fields map[string]interface{},
+	tm time.Time,
+	tp ...telegraf.ValueType,
+) telegraf.Metric {
+	m, err := metric.New(name, tags, fields, tm, tp...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
 // TestMetric Returns a simple test point:
 //     measurement -> "test1" or name
 //     tags -> "tag1":"value1"